@@ -10,6 +10,7 @@ import (
 )
 
 // Encoder encodes audio frames to a writer.
+// When there is more than one channel, frames are interleaved (ex: left, right, left, right, etc.).
 type Encoder interface {
 	Encode(w io.Writer, frames []float64) error
 }
@@ -26,6 +27,9 @@ type Player interface {
 
 // Frames returns audio frames generated using the provided sound wave.
 // The provided sample rate is in number of frames per second (= hertz).
+//
+// The frame with index i is the value of the wave at startOffset + i * time.Second / sampleRate,
+// the number of frames for a given duration is given by FrameCount.
 func Frames(wave sound.Wave, sampleRate int, startOffset, duration time.Duration) ([]float64, error) {
 	if wave == nil {
 		return nil, errors.New("wave is not defined, unable to get frames")
@@ -34,16 +38,89 @@ func Frames(wave sound.Wave, sampleRate int, startOffset, duration time.Duration
 		return nil, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", sampleRate)
 	}
 
-	frames := []float64{}
-	step := float64(time.Second) / float64(sampleRate)
-	for i := float64(startOffset); i < float64(startOffset+duration); i += step {
-		val, err := wave.Value(time.Duration(i))
+	frames := make([]float64, FrameCount(sampleRate, duration))
+	for i := range frames {
+		val, err := wave.Value(sound.FrameTime(startOffset, i, sampleRate))
 		if err != nil {
 			return nil, err
 		}
 
-		frames = append(frames, val)
+		frames[i] = val
 	}
 
 	return frames, nil
 }
+
+// ProcessorFrames returns the mono audio frames rendered by the provided processor, in a single block.
+// The processor is expected to render frames at the given sample rate.
+func ProcessorFrames(p sound.Processor, sampleRate int, duration time.Duration) ([]float64, error) {
+	if p == nil {
+		return nil, errors.New("processor is not defined, unable to get frames")
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", sampleRate)
+	}
+
+	frames := make([]float64, FrameCount(sampleRate, duration))
+	if err := p.Process(frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// StereoFrames returns interleaved audio frames (left, right, left, right, etc.) generated using the provided stereo wave.
+// Use sound.UpMix to render a mono wave in stereo.
+func StereoFrames(wave sound.StereoWave, sampleRate int, startOffset, duration time.Duration) ([]float64, error) {
+	if wave == nil {
+		return nil, errors.New("wave is not defined, unable to get frames")
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", sampleRate)
+	}
+
+	frames := make([]float64, 2*FrameCount(sampleRate, duration))
+	for i := 0; i < len(frames); i += 2 {
+		left, right, err := wave.StereoValue(sound.FrameTime(startOffset, i/2, sampleRate))
+		if err != nil {
+			return nil, err
+		}
+
+		frames[i], frames[i+1] = left, right
+	}
+
+	return frames, nil
+}
+
+// Interleave combines the frames of several channels into a single slice of interleaved frames.
+// It can be used to encode any number of channels, each rendered with Frames.
+// Channels that are shorter than the others are padded with silence.
+func Interleave(channels ...[]float64) []float64 {
+	length := 0
+	for _, frames := range channels {
+		if len(frames) > length {
+			length = len(frames)
+		}
+	}
+
+	out := make([]float64, length*len(channels))
+	for c, frames := range channels {
+		for i, frame := range frames {
+			out[i*len(channels)+c] = frame
+		}
+	}
+	return out
+}
+
+// FrameCount returns the number of frames needed to render a duration at the given sample rate.
+// It is the number of frames whose time is in the [0, duration) range, that is duration * sampleRate / time.Second rounded up.
+// For ex: one second at 44100 Hz is exactly 44100 frames and one millisecond at 44100 Hz is 45 frames.
+func FrameCount(sampleRate int, duration time.Duration) int {
+	if duration <= 0 || sampleRate <= 0 {
+		return 0
+	}
+	// split the computation in whole seconds and the rest to avoid overflows
+	rate := int64(sampleRate)
+	whole := int64(duration/time.Second) * rate
+	rest := int64(duration%time.Second)*rate + int64(time.Second) - 1
+	return int(whole + rest/int64(time.Second))
+}
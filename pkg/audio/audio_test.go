@@ -34,6 +34,10 @@ func TestFrames(t *testing.T) {
 			{sampleRate: 10, duration: time.Second, want: 10},
 			{sampleRate: 10, duration: 2 * time.Second, want: 20},
 			{sampleRate: 100, duration: 10 * time.Second, want: 1000},
+			{sampleRate: 44100, duration: time.Second, want: 44100},
+			{sampleRate: 44100, duration: 3 * time.Second, want: 3 * 44100},
+			{sampleRate: 44100, duration: 10 * time.Millisecond, want: 441},
+			{sampleRate: 44100, duration: time.Millisecond, want: 45},
 		}
 
 		for _, test := range tests {
@@ -69,3 +73,80 @@ func TestFrames(t *testing.T) {
 		}
 	})
 }
+
+func TestFrameTiming(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should not drift over long durations", func(t *testing.T) {
+		var times []time.Duration
+		wave := recordingWave(func(at time.Duration) { times = append(times, at) })
+		frames, err := Frames(wave, 44100, 0, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(frames) != 60*44100 {
+			t.Fatalf("want %d frames but got %d", 60*44100, len(frames))
+		}
+		// the frame played after exactly 59 seconds should be at exactly 59 seconds
+		if got := times[59*44100]; got != 59*time.Second {
+			t.Fatalf("want frame at %s but got %s", 59*time.Second, got)
+		}
+	})
+}
+
+// recordingWave is a silent wave that calls the provided function with the time of each requested value.
+type recordingWave func(at time.Duration)
+
+func (w recordingWave) Value(at time.Duration) (float64, error) {
+	w(at)
+	return 0, nil
+}
+
+func TestStereoFrames(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should generate interleaved frames", func(t *testing.T) {
+		wave := sound.NewDualWave(&sound.MockWave{}, &sound.SilentWave{})
+		frames, err := StereoFrames(wave, 4, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []float64{1, 0, 1, 0, 1, 0, 1, 0}
+		if len(frames) != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), len(frames))
+		}
+		for i := range want {
+			if frames[i] != want[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], frames[i])
+			}
+		}
+	})
+
+	t.Run("Should validate required inputs", func(t *testing.T) {
+		_, err := StereoFrames(nil, 1, 0, time.Second)
+		if err == nil {
+			t.Fatal("want an error for a nil wave")
+		}
+		_, err = StereoFrames(sound.UpMix(&sound.MockWave{}), 0, 0, time.Second)
+		if err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+	})
+}
+
+func TestInterleave(t *testing.T) {
+	t.Parallel()
+
+	got := Interleave([]float64{1, 2, 3}, []float64{4, 5}, []float64{6, 7, 8})
+	want := []float64{1, 4, 6, 2, 5, 7, 3, 0, 8}
+	if len(got) != len(want) {
+		t.Fatalf("want %d frames but got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// CommandTemplate describes how to launch a command line program that plays raw PCM frames from its standard input.
+//
+// Arguments can contain the following placeholders, they are replaced when the command is launched:
+//   - {format}: name of the sample format, see the FormatNames field
+//   - {rate}: sample rate (ex: 44100)
+//   - {channels}: number of channels (ex: 2)
+//   - {layout}: ffmpeg channel layout (ex: stereo), for the -ch_layout option of ffmpeg 5.1 or later
+//   - {bits}: number of bits per frame (ex: 16)
+//   - {encoding}: "signed-integer" or "floating-point"
+//   - {endian}: "little" or "big"
+type CommandTemplate struct {
+	Name string   // name or path of the executable
+	Args []string // arguments, with placeholders
+
+	// FormatNames lists the sample formats supported by the command and the names used for the {format} placeholder.
+	// If it is nil, all formats are supported and the {format} placeholder is replaced by the format's name (ex: s16le).
+	FormatNames map[SampleFormat]string
+}
+
+// Supports reports whether the command can play frames in the given format.
+func (c CommandTemplate) Supports(format SampleFormat) bool {
+	if format.validate() != nil {
+		return false
+	}
+	if c.FormatNames == nil {
+		return true
+	}
+	_, ok := c.FormatNames[format]
+	return ok
+}
+
+// Command returns the arguments of the command with all placeholders replaced.
+func (c CommandTemplate) Command(format SampleFormat, sampleRate, numChannels int) []string {
+	formatName := string(format)
+	if name, ok := c.FormatNames[format]; ok {
+		formatName = name
+	}
+	encoding := "signed-integer"
+	if format.IsFloat() {
+		encoding = "floating-point"
+	}
+	endian := "little"
+	if sampleFormatSpecs[format].order == binary.BigEndian {
+		endian = "big"
+	}
+
+	replacer := strings.NewReplacer(
+		"{format}", formatName,
+		"{rate}", strconv.Itoa(sampleRate),
+		"{channels}", strconv.Itoa(numChannels),
+		"{layout}", channelLayout(numChannels),
+		"{bits}", strconv.Itoa(8*format.Size()),
+		"{encoding}", encoding,
+		"{endian}", endian,
+	)
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = replacer.Replace(arg)
+	}
+	return args
+}
+
+// channelLayout returns the name of the ffmpeg channel layout for a given number of channels.
+func channelLayout(numChannels int) string {
+	switch numChannels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	}
+	return strconv.Itoa(numChannels) + "c"
+}
+
+// Presets for common command line players.
+var (
+	// FFPlayCommand plays frames with ffplay (from ffmpeg).
+	FFPlayCommand = CommandTemplate{
+		Name: "ffplay",
+		Args: []string{
+			"-hide_banner", "-loglevel", "error", "-autoexit", "-showmode", "1",
+			"-f", "{format}", "-ar", "{rate}", "-ac", "{channels}", "-i", "pipe:0",
+		},
+	}
+
+	// PwPlayCommand plays frames with pw-play (from PipeWire).
+	PwPlayCommand = CommandTemplate{
+		Name: "pw-play",
+		Args: []string{"--raw", "--format", "{format}", "--rate", "{rate}", "--channels", "{channels}", "-"},
+		FormatNames: map[SampleFormat]string{
+			FormatS16LE: "s16",
+			FormatS24LE: "s24",
+			FormatS32LE: "s32",
+			FormatF32LE: "f32",
+			FormatF64LE: "f64",
+		},
+	}
+
+	// PaplayCommand plays frames with paplay (from PulseAudio).
+	PaplayCommand = CommandTemplate{
+		Name: "paplay",
+		Args: []string{"--raw", "--format={format}", "--rate={rate}", "--channels={channels}"},
+		FormatNames: map[SampleFormat]string{
+			FormatS16LE: "s16le",
+			FormatS16BE: "s16be",
+			FormatS24LE: "s24le",
+			FormatS24BE: "s24be",
+			FormatS32LE: "s32le",
+			FormatS32BE: "s32be",
+			FormatF32LE: "float32le",
+			FormatF32BE: "float32be",
+		},
+	}
+
+	// AplayCommand plays frames with aplay (from ALSA).
+	AplayCommand = CommandTemplate{
+		Name: "aplay",
+		Args: []string{"-q", "-t", "raw", "-f", "{format}", "-r", "{rate}", "-c", "{channels}", "-"},
+		FormatNames: map[SampleFormat]string{
+			FormatS16LE: "S16_LE",
+			FormatS16BE: "S16_BE",
+			FormatS24LE: "S24_3LE",
+			FormatS24BE: "S24_3BE",
+			FormatS32LE: "S32_LE",
+			FormatS32BE: "S32_BE",
+			FormatF32LE: "FLOAT_LE",
+			FormatF32BE: "FLOAT_BE",
+			FormatF64LE: "FLOAT64_LE",
+			FormatF64BE: "FLOAT64_BE",
+		},
+	}
+
+	// SoxPlayCommand plays frames with play (from SoX).
+	SoxPlayCommand = CommandTemplate{
+		Name: "play",
+		Args: []string{
+			"-q", "-t", "raw", "-r", "{rate}", "-c", "{channels}",
+			"-e", "{encoding}", "-b", "{bits}", "--endian", "{endian}", "-",
+		},
+	}
+)
+
+// CommandPresets lists the preset commands in the order they are looked for by DetectCommand.
+var CommandPresets = []CommandTemplate{FFPlayCommand, PwPlayCommand, PaplayCommand, AplayCommand, SoxPlayCommand}
+
+// ErrNoCommandFound means that none of the commands could be found.
+var ErrNoCommandFound = errors.New("no player command found")
+
+// DetectCommand returns the first command whose executable can be found in the PATH.
+// If no commands are provided, the CommandPresets are used.
+func DetectCommand(commands ...CommandTemplate) (CommandTemplate, error) {
+	if len(commands) == 0 {
+		commands = CommandPresets
+	}
+	for _, command := range commands {
+		if _, err := exec.LookPath(command.Name); err == nil {
+			return command, nil
+		}
+	}
+	return CommandTemplate{}, ErrNoCommandFound
+}
+
+// ErrPlayerCommand means that the player command failed.
+var ErrPlayerCommand = errors.New("failed to execute player command")
+
+// CommandPlayer plays the provided wave with any command line program that reads raw PCM frames from its standard input.
+// Frames are rendered block by block and streamed to the command.
+//
+// The StereoWave field can be set instead of the Wave field to play in stereo.
+type CommandPlayer struct {
+	Command             CommandTemplate // if its name is empty, the command is found with DetectCommand
+	Wave                sound.Wave
+	StereoWave          sound.StereoWave
+	SampleRate          int
+	DurationStartOffset time.Duration
+	Duration            time.Duration
+	Format              SampleFormat // format of the streamed frames, defaults to FormatS16LE (supported by all presets)
+}
+
+// Play plays the wave until the end.
+func (p CommandPlayer) Play() error {
+	return p.PlayContext(context.Background())
+}
+
+// PlayContext plays the wave, playback is stopped early if the context is canceled
+// (in which case the context's error is returned).
+func (p CommandPlayer) PlayContext(ctx context.Context) error {
+	stream, err := newPlayerStream(p.Wave, p.StereoWave, p.SampleRate, p.DurationStartOffset, p.Duration)
+	if err != nil {
+		return err
+	}
+	format := p.Format
+	if format == "" {
+		format = FormatS16LE
+	}
+
+	command := p.Command
+	if command.Name == "" {
+		command, err = DetectCommand()
+		if err != nil {
+			return err
+		}
+	}
+	if !command.Supports(format) {
+		return fmt.Errorf("sample format %q is not supported by %s", string(format), command.Name)
+	}
+
+	args := command.Command(format, p.SampleRate, stream.NumChannels())
+	err = runPipeCommand(ctx, command.Name, args, stream, format)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%w: %s: %s", ErrPlayerCommand, command.Name, err)
+	}
+	return err
+}
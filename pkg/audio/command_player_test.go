@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestCommandTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should replace placeholders", func(t *testing.T) {
+		tests := []struct {
+			command CommandTemplate
+			format  SampleFormat
+			want    string
+		}{
+			{command: FFPlayCommand, format: FormatS16LE, want: "-f s16le -ar 44100 -ac 2 -i pipe:0"},
+			{command: PwPlayCommand, format: FormatF32LE, want: "--raw --format f32 --rate 44100 --channels 2 -"},
+			{command: PaplayCommand, format: FormatF32LE, want: "--raw --format=float32le --rate=44100 --channels=2"},
+			{command: AplayCommand, format: FormatS24LE, want: "-q -t raw -f S24_3LE -r 44100 -c 2 -"},
+			{command: SoxPlayCommand, format: FormatS16BE, want: "-r 44100 -c 2 -e signed-integer -b 16 --endian big -"},
+			{command: SoxPlayCommand, format: FormatF64LE, want: "-e floating-point -b 64 --endian little -"},
+		}
+
+		for _, test := range tests {
+			got := strings.Join(test.command.Command(test.format, 44100, 2), " ")
+			if !strings.Contains(got, test.want) {
+				t.Fatalf("%s: want arguments that include %q but got %q", test.command.Name, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should report supported formats", func(t *testing.T) {
+		for _, command := range CommandPresets {
+			if !command.Supports(FormatS16LE) {
+				t.Fatalf("%s should support %s", command.Name, FormatS16LE)
+			}
+			if command.Supports("unknown") {
+				t.Fatalf("%s should not support unknown formats", command.Name)
+			}
+		}
+		if PaplayCommand.Supports(FormatF64LE) {
+			t.Fatalf("paplay should not support %s", FormatF64LE)
+		}
+	})
+}
+
+func TestDetectCommand(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	missing := CommandTemplate{Name: "musigo-missing-player"}
+	found := CommandTemplate{Name: "sh"}
+
+	got, err := DetectCommand(missing, found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != found.Name {
+		t.Fatalf("want command %s but got %s", found.Name, got.Name)
+	}
+
+	_, err = DetectCommand(missing)
+	if !errors.Is(err, ErrNoCommandFound) {
+		t.Fatalf("want error %s but got %v", ErrNoCommandFound, err)
+	}
+}
+
+func TestCommandPlayer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Player interface", func(t *testing.T) {
+		var _ Player = CommandPlayer{}
+	})
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	t.Run("Should stream frames to the command", func(t *testing.T) {
+		player := CommandPlayer{
+			Command:    CommandTemplate{Name: "sh", Args: []string{"-c", "test $(wc -c) -eq 1600"}},
+			StereoWave: sound.UpMix(&sound.MockWave{}),
+			SampleRate: 400,
+			Duration:   time.Second,
+		}
+		// 400 stereo frames of 16 bits = 1600 bytes
+		if err := player.Play(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Should validate the sample format", func(t *testing.T) {
+		player := CommandPlayer{
+			Command:    PaplayCommand,
+			Wave:       &sound.MockWave{},
+			SampleRate: 44100,
+			Duration:   time.Second,
+			Format:     FormatF64LE,
+		}
+		err := player.Play()
+		if err == nil || errors.Is(err, ErrPlayerCommand) {
+			t.Fatalf("want a validation error but got %v", err)
+		}
+	})
+
+	t.Run("Should report command failures", func(t *testing.T) {
+		player := CommandPlayer{
+			Command:    CommandTemplate{Name: "sh", Args: []string{"-c", "echo no audio device >&2; exit 1"}},
+			Wave:       &sound.MockWave{},
+			SampleRate: 8000,
+			Duration:   time.Second,
+		}
+		err := player.Play()
+		if !errors.Is(err, ErrPlayerCommand) || !strings.Contains(err.Error(), "no audio device") {
+			t.Fatalf("want error %s with the command output but got %v", ErrPlayerCommand, err)
+		}
+	})
+}
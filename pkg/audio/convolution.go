@@ -0,0 +1,274 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/maths"
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// ConvolutionBlockSize is the number of frames in each partition of the impulse response of a convolution.
+// The first partition is convolved frame by frame, so the convolution has no latency,
+// the following partitions are convolved in the frequency domain once per block.
+const ConvolutionBlockSize = 256
+
+// ErrEmptyImpulseResponse is returned when creating a convolution with a nil or empty impulse response.
+var ErrEmptyImpulseResponse = errors.New("empty impulse response")
+
+// Convolution is an effect that convolves a wave with the impulse response of a room, a speaker cabinet, etc.
+// (usually recorded in an audio file),
+// using uniformly partitioned FFT convolution so that long impulse responses can be rendered in reasonable time.
+// It is a stateful effect (see sound.FrameClock).
+//
+// You must call NewConvolution to create a convolution.
+type Convolution struct {
+	wave       sound.Wave
+	stereoWave sound.StereoWave
+	sampleRate int
+	mix        float64 // from 0 (dry only) to 1 (wet only)
+	mono       *convolutionKernel
+	left       *convolutionKernel
+	right      *convolutionKernel
+	state      *convolutionState
+}
+
+// NewConvolution creates a convolution for waves rendered at the given sample rate,
+// the impulse response is resampled when its sample rate is different.
+// The mix goes from 0 (only the input wave) to 1 (only the convolved wave).
+//
+// Mono waves are convolved with the mix-down of the channels of the impulse response,
+// the left and right channels of stereo waves are convolved with the first two channels of the impulse response
+// (or with its single channel for a mono impulse response).
+func NewConvolution(impulseResponse *Sample, sampleRate int, mix float64) (Convolution, error) {
+	if sampleRate <= 0 {
+		return Convolution{}, fmt.Errorf("invalid sample rate: %d, sample rate must be positive", sampleRate)
+	}
+	if impulseResponse == nil || impulseResponse.Len() == 0 {
+		return Convolution{}, ErrEmptyImpulseResponse
+	}
+	if impulseResponse.SampleRate() != sampleRate {
+		resampled, err := Resample(impulseResponse, sampleRate)
+		if err != nil {
+			return Convolution{}, fmt.Errorf("resample impulse response: %w", err)
+		}
+		impulseResponse = resampled
+	}
+
+	mono := make([]float64, impulseResponse.Len())
+	for c := 0; c < impulseResponse.NumChannels(); c++ {
+		for i, v := range impulseResponse.Channel(c) {
+			mono[i] += v / float64(impulseResponse.NumChannels())
+		}
+	}
+	conv := Convolution{
+		sampleRate: sampleRate,
+		mix:        math.Max(0, math.Min(1, mix)),
+		mono:       newConvolutionKernel(mono),
+	}
+	conv.left, conv.right = conv.mono, conv.mono
+	if impulseResponse.NumChannels() > 1 {
+		conv.left = newConvolutionKernel(impulseResponse.Channel(0))
+		conv.right = newConvolutionKernel(impulseResponse.Channel(1))
+	}
+	return conv, nil
+}
+
+func (c Convolution) Wrap(wave sound.Wave) sound.Wave {
+	c.wave = wave
+	c.state = &convolutionState{}
+	return c
+}
+
+// WrapStereo returns a stereo wave with the convolution of each channel of the stereo wave.
+func (c Convolution) WrapStereo(wave sound.StereoWave) sound.StereoWave {
+	c.stereoWave = wave
+	c.state = &convolutionState{}
+	return c
+}
+
+func (c Convolution) Serial() bool {
+	return true
+}
+
+func (c Convolution) Value(at time.Duration) (float64, error) {
+	if c.wave == nil || c.state == nil || c.mono == nil {
+		return 0, sound.ErrNoWave
+	}
+	s := c.state
+	switch s.clock.Advance(at) {
+	case sound.ClockRepeat:
+		return s.left.out, nil
+	case sound.ClockReset:
+		s.left = newConvolver(c.mono)
+	}
+
+	x, err := c.wave.Value(at)
+	if err != nil && !errors.Is(err, sound.ErrEndOfWave) {
+		return 0, err
+	}
+	return s.left.process(x, c.mix), nil
+}
+
+func (c Convolution) StereoValue(at time.Duration) (float64, float64, error) {
+	if c.stereoWave == nil || c.state == nil || c.mono == nil {
+		return 0, 0, sound.ErrNoWave
+	}
+	s := c.state
+	switch s.clock.Advance(at) {
+	case sound.ClockRepeat:
+		return s.left.out, s.right.out, nil
+	case sound.ClockReset:
+		s.left, s.right = newConvolver(c.left), newConvolver(c.right)
+	}
+
+	left, right, err := c.stereoWave.StereoValue(at)
+	if err != nil && !errors.Is(err, sound.ErrEndOfWave) {
+		return 0, 0, err
+	}
+	return s.left.process(left, c.mix), s.right.process(right, c.mix), nil
+}
+
+// WrapProcessor returns a processor that convolves the frames of the input processor.
+func (c Convolution) WrapProcessor(p sound.Processor) sound.Processor {
+	return &convolutionProcessor{input: p, params: c}
+}
+
+type convolutionProcessor struct {
+	input     sound.Processor
+	params    Convolution
+	convolver *convolver
+}
+
+func (p *convolutionProcessor) Process(buf []float64) error {
+	if p.params.mono == nil {
+		return ErrEmptyImpulseResponse
+	}
+	if p.convolver == nil {
+		p.convolver = newConvolver(p.params.mono)
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.convolver.process(x, p.params.mix)
+	}
+	return nil
+}
+
+func (p *convolutionProcessor) Reset() {
+	p.input.Reset()
+	p.convolver = nil
+}
+
+type convolutionState struct {
+	clock       sound.FrameClock
+	left, right *convolver
+}
+
+// convolutionKernel holds an impulse response split in partitions of ConvolutionBlockSize frames.
+// It is never modified once created, so it is shared by all the convolvers.
+type convolutionKernel struct {
+	head    []float64      // first partition, convolved in the time domain
+	spectra [][]complex128 // spectra of the following partitions, zero-padded to twice the block size
+}
+
+func newConvolutionKernel(impulseResponse []float64) *convolutionKernel {
+	k := &convolutionKernel{head: impulseResponse}
+	if len(impulseResponse) <= ConvolutionBlockSize {
+		return k
+	}
+	k.head = impulseResponse[:ConvolutionBlockSize]
+	for start := ConvolutionBlockSize; start < len(impulseResponse); start += ConvolutionBlockSize {
+		spectrum := make([]complex128, 2*ConvolutionBlockSize)
+		for i := 0; i < ConvolutionBlockSize && start+i < len(impulseResponse); i++ {
+			spectrum[i] = complex(impulseResponse[start+i], 0)
+		}
+		_ = maths.FFT(spectrum) // the length is always a power of two
+		k.spectra = append(k.spectra, spectrum)
+	}
+	return k
+}
+
+// convolver convolves frames with a kernel, one frame at a time.
+//
+// The head of the kernel is convolved directly with the latest frames.
+// Each time a block of frames is complete, its spectrum is computed (overlapping with the previous block),
+// and the contribution of the other partitions to the next block is computed with the spectra of the previous blocks.
+type convolver struct {
+	kernel  *convolutionKernel
+	input   []float64      // previous block followed by the current block
+	pos     int            // position in the current block
+	history [][]complex128 // spectra of the latest complete blocks, in a ring
+	latest  int            // index of the spectrum of the latest complete block in the history
+	tail    []float64      // contribution of the partitions after the head to the current block
+	scratch []complex128
+	out     float64 // last output
+}
+
+func newConvolver(kernel *convolutionKernel) *convolver {
+	c := &convolver{
+		kernel: kernel,
+		input:  make([]float64, 2*ConvolutionBlockSize),
+		tail:   make([]float64, ConvolutionBlockSize),
+	}
+	if len(kernel.spectra) > 0 {
+		c.history = make([][]complex128, len(kernel.spectra))
+		for i := range c.history {
+			c.history[i] = make([]complex128, 2*ConvolutionBlockSize)
+		}
+		c.scratch = make([]complex128, 2*ConvolutionBlockSize)
+	}
+	return c
+}
+
+// process feeds the input frame to the convolver and returns the mix of the frame and of the convolution.
+func (c *convolver) process(x, mix float64) float64 {
+	current := ConvolutionBlockSize + c.pos
+	c.input[current] = x
+
+	wet := c.tail[c.pos]
+	for i, h := range c.kernel.head {
+		wet += h * c.input[current-i]
+	}
+	c.out = (1-mix)*x + mix*wet
+
+	c.pos++
+	if c.pos == ConvolutionBlockSize {
+		c.nextBlock()
+	}
+	return c.out
+}
+
+// nextBlock stores the spectrum of the block that was just completed
+// and computes the contribution of the partitions after the head to the next block (overlap-save).
+func (c *convolver) nextBlock() {
+	if len(c.history) > 0 {
+		c.latest = (c.latest + 1) % len(c.history)
+		spectrum := c.history[c.latest]
+		for i, v := range c.input {
+			spectrum[i] = complex(v, 0)
+		}
+		_ = maths.FFT(spectrum)
+
+		for i := range c.scratch {
+			c.scratch[i] = 0
+		}
+		for p, partition := range c.kernel.spectra {
+			// the partition p+1 of the kernel applies to the block completed p blocks ago
+			block := c.history[(c.latest-p+len(c.history))%len(c.history)]
+			for i := range c.scratch {
+				c.scratch[i] += block[i] * partition[i]
+			}
+		}
+		_ = maths.InverseFFT(c.scratch)
+		for i := range c.tail {
+			c.tail[i] = real(c.scratch[ConvolutionBlockSize+i])
+		}
+	}
+
+	copy(c.input, c.input[ConvolutionBlockSize:])
+	c.pos = 0
+}
@@ -0,0 +1,157 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestConvolution(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 8000
+	frameAt := func(i int) time.Duration {
+		return sound.FrameTime(0, i, sampleRate)
+	}
+
+	// random frames (from -1 to 1)
+	noise := func(seed int64, length int) []float64 {
+		r := rand.New(rand.NewSource(seed))
+		out := make([]float64, length)
+		for i := range out {
+			out[i] = r.Float64()*2 - 1
+		}
+		return out
+	}
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ sound.Effect = Convolution{}
+		var _ sound.StereoEffect = Convolution{}
+		var _ sound.ProcessorEffect = Convolution{}
+	})
+
+	t.Run("Should convolve the wave with the impulse response", func(t *testing.T) {
+		// several partitions, the last one being incomplete
+		ir := noise(1, 3*ConvolutionBlockSize+50)
+		input := noise(2, 1000)
+		conv, err := NewConvolution(NewSample(ir, sampleRate), sampleRate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wave := conv.Wrap(NewSample(input, sampleRate))
+
+		for i := 0; i < len(input)+len(ir); i++ {
+			want := 0.0
+			for j, h := range ir {
+				if i-j >= 0 && i-j < len(input) {
+					want += h * input[i-j]
+				}
+			}
+			got, err := wave.Value(frameAt(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-want) > 0.000000001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want, got)
+			}
+		}
+	})
+
+	t.Run("Should mix the wave with the convolution", func(t *testing.T) {
+		// the impulse response repeats the wave after 500 frames
+		ir := make([]float64, 501)
+		ir[500] = 1
+		conv, _ := NewConvolution(NewSample(ir, sampleRate), sampleRate, 0.25)
+		wave := conv.Wrap(NewSample([]float64{1}, sampleRate))
+
+		want := map[int]float64{0: 0.75, 500: 0.25}
+		for i := 0; i < 1000; i++ {
+			got, err := wave.Value(frameAt(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-want[i]) > 0.000000001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got)
+			}
+		}
+	})
+
+	t.Run("Should convolve each channel with its own impulse response", func(t *testing.T) {
+		// interleaved: the left channel is delayed by 10 frames and the right one by 700 frames
+		ir := make([]float64, 2*701)
+		ir[2*10] = 1
+		ir[2*700+1] = 0.5
+		conv, _ := NewConvolution(NewMultiChannelSample(ir, sampleRate, 2), sampleRate, 1)
+		wave := conv.WrapStereo(sound.UpMix(NewSample([]float64{1}, sampleRate)))
+
+		type frame struct{ left, right float64 }
+		want := map[int]frame{10: {left: 1}, 700: {right: 0.5}}
+		for i := 0; i < 1000; i++ {
+			left, right, err := wave.StereoValue(frameAt(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(left-want[i].left) > 0.000000001 || math.Abs(right-want[i].right) > 0.000000001 {
+				t.Fatalf("unexpected frame at index %d, want %+v but got (%f, %f)", i, want[i], left, right)
+			}
+		}
+	})
+
+	t.Run("Should resample the impulse response", func(t *testing.T) {
+		// a delay of 200 frames at twice the sample rate is a delay of 100 frames
+		ir := make([]float64, 400)
+		ir[200] = 1
+		conv, err := NewConvolution(NewSample(ir, 2*sampleRate), sampleRate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wave := conv.Wrap(NewSample([]float64{1}, sampleRate))
+
+		peak, peakIndex := 0.0, 0
+		for i := 0; i < 300; i++ {
+			got, _ := wave.Value(frameAt(i))
+			if math.Abs(got) > peak {
+				peak, peakIndex = math.Abs(got), i
+			}
+		}
+		if peakIndex != 100 {
+			t.Fatalf("want the peak at index 100 but got %d", peakIndex)
+		}
+	})
+
+	t.Run("Should reject empty impulse responses", func(t *testing.T) {
+		if _, err := NewConvolution(NewSample(nil, sampleRate), sampleRate, 1); !errors.Is(err, ErrEmptyImpulseResponse) {
+			t.Fatalf("want %q but got %v", ErrEmptyImpulseResponse, err)
+		}
+		if _, err := NewConvolution(nil, sampleRate, 1); !errors.Is(err, ErrEmptyImpulseResponse) {
+			t.Fatalf("want %q for a nil impulse response but got %v", ErrEmptyImpulseResponse, err)
+		}
+		if _, err := NewConvolution(NewSample([]float64{1}, sampleRate), 0, 1); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+	})
+
+	t.Run("Should reset its state and process frames like waves", func(t *testing.T) {
+		source := sound.NewSynthWave(sound.SawTooth{}, 3)
+		conv, _ := NewConvolution(NewSample(noise(3, 2*ConvolutionBlockSize+10), sampleRate), sampleRate, 0.5)
+
+		want := conv.Wrap(source)
+		got := sound.NewProcessorWave(conv.WrapProcessor(sound.NewWaveProcessor(source, sampleRate, 0)), sampleRate)
+		for pass := 0; pass < 2; pass++ {
+			for i := 0; i < sampleRate/4; i++ {
+				w, _ := want.Value(frameAt(i))
+				g, err := got.Value(frameAt(i))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g != w {
+					t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, w, g)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// FFPlayPipePlayer uses ffplay to play the provided wave.
+// Unlike FFPlayPlayer, frames are rendered block by block and streamed to ffplay's standard input,
+// so no file is created and playback starts right away.
+//
+// The StereoWave field can be set instead of the Wave field to play in stereo.
+type FFPlayPipePlayer struct {
+	Wave                sound.Wave
+	StereoWave          sound.StereoWave
+	SampleRate          int
+	DurationStartOffset time.Duration
+	Duration            time.Duration
+	Format              SampleFormat // format of the streamed frames, defaults to DefaultSampleFormat
+}
+
+// Play plays the wave until the end.
+func (p FFPlayPipePlayer) Play() error {
+	return p.PlayContext(context.Background())
+}
+
+// PlayContext plays the wave, playback is stopped early if the context is canceled
+// (in which case the context's error is returned).
+func (p FFPlayPipePlayer) PlayContext(ctx context.Context) error {
+	stream, err := newPlayerStream(p.Wave, p.StereoWave, p.SampleRate, p.DurationStartOffset, p.Duration)
+	if err != nil {
+		return err
+	}
+	format := p.Format.orDefault()
+	if err := format.validate(); err != nil {
+		return err
+	}
+
+	args := newFFPlayPipeArgs(format, p.SampleRate, stream.NumChannels())
+	err = runPipeCommand(ctx, "ffplay", args, stream, format)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%w: %s", ErrFFPlayCommand, err)
+	}
+	return err
+}
+
+// ErrFFMpegCommand means that the ffmpeg command failed.
+var ErrFFMpegCommand = errors.New("failed to execute ffmpeg command")
+
+// FFMpegExporter uses ffmpeg to encode the provided wave to an audio file.
+// The file format is guessed by ffmpeg from the file extension (for ex: .mp3, .ogg, .flac).
+// Frames are rendered block by block and streamed to ffmpeg's standard input.
+//
+// The StereoWave field can be set instead of the Wave field to export in stereo.
+type FFMpegExporter struct {
+	Wave                sound.Wave
+	StereoWave          sound.StereoWave
+	SampleRate          int
+	DurationStartOffset time.Duration
+	Duration            time.Duration
+	Format              SampleFormat // format of the streamed frames, defaults to DefaultSampleFormat
+	Filepath            string       // output file, it is overwritten if it already exists
+	OutputArgs          []string     // additional ffmpeg output options (for ex: "-b:a", "192k")
+}
+
+// Export encodes the wave to the output file.
+func (e FFMpegExporter) Export() error {
+	return e.ExportContext(context.Background())
+}
+
+// ExportContext encodes the wave to the output file, encoding is stopped early if the context is canceled
+// (in which case the context's error is returned).
+func (e FFMpegExporter) ExportContext(ctx context.Context) error {
+	if e.Filepath == "" {
+		return errors.New("no output file path was provided")
+	}
+	stream, err := newPlayerStream(e.Wave, e.StereoWave, e.SampleRate, e.DurationStartOffset, e.Duration)
+	if err != nil {
+		return err
+	}
+	format := e.Format.orDefault()
+	if err := format.validate(); err != nil {
+		return err
+	}
+
+	args := newFFMpegArgs(format, e.SampleRate, stream.NumChannels(), e.OutputArgs, e.Filepath)
+	err = runPipeCommand(ctx, "ffmpeg", args, stream, format)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%w: %s", ErrFFMpegCommand, err)
+	}
+	return err
+}
+
+// newPlayerStream validates the common inputs of players and exporters and creates the stream to render.
+func newPlayerStream(wave sound.Wave, stereoWave sound.StereoWave, sampleRate int, startOffset, duration time.Duration) (*Stream, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate must be positive", sampleRate)
+	}
+	if wave == nil && stereoWave == nil {
+		return nil, errors.New("no wave was provided")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid duration: %s", duration)
+	}
+	if stereoWave != nil {
+		return NewStereoStream(stereoWave, sampleRate, startOffset, duration)
+	}
+	return NewStream(wave, sampleRate, startOffset, duration)
+}
+
+// newFFPlayPipeArgs returns the arguments used to play frames from the standard input with ffplay.
+func newFFPlayPipeArgs(format SampleFormat, sampleRate, numChannels int) []string {
+	return FFPlayCommand.Command(format, sampleRate, numChannels)
+}
+
+// newFFMpegArgs returns the arguments used to encode frames from the standard input to a file with ffmpeg.
+func newFFMpegArgs(format SampleFormat, sampleRate, numChannels int, outputArgs []string, filepath string) []string {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-y",
+		"-f", string(format),
+		"-ar", strconv.Itoa(sampleRate),
+		"-ac", strconv.Itoa(numChannels), // -ch_layout is only supported since ffmpeg 5.1
+		"-i", "pipe:0",
+	}
+	args = append(args, outputArgs...)
+	return append(args, filepath)
+}
+
+// runPipeCommand runs a command and writes the frames of the stream to its standard input.
+// The command is killed if the context is canceled.
+// When the command fails, the returned error includes what the command wrote to its standard error.
+func runPipeCommand(ctx context.Context, name string, args []string, stream *Stream, format SampleFormat) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	// the standard error is read from our own pipe: with a buffer, cmd.Wait would also wait
+	// for the child processes of a killed command (which aren't killed) to close it.
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer stderrReader.Close()
+	cmd.Stderr = stderrWriter
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		stderrWriter.Close()
+		return err
+	}
+	err = cmd.Start()
+	stderrWriter.Close()
+	if err != nil {
+		return err
+	}
+	stderr := &bytes.Buffer{}
+	stderrDone := make(chan struct{})
+	go func() {
+		io.Copy(stderr, stderrReader)
+		close(stderrDone)
+	}()
+
+	// the standard input is closed when the context is canceled,
+	// so that encoding stops even if a child process of the command still holds it.
+	encoded := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			stdin.Close()
+		case <-encoded:
+		}
+	}()
+
+	// the encoding error is reported after waiting for the command
+	// as it is most likely caused by the command exiting early.
+	encodeErr := (&PCMEncoder{Format: format}).EncodeStream(stdin, stream)
+	close(encoded)
+	stdin.Close()
+	waitErr := cmd.Wait()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	<-stderrDone
+	if waitErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", waitErr, msg)
+		}
+		return waitErr
+	}
+	if encodeErr != nil {
+		return fmt.Errorf("failed to stream frames: %w", encodeErr)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestFFPlayPipePlayer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Player interface", func(t *testing.T) {
+		var _ Player = FFPlayPipePlayer{}
+	})
+
+	t.Run("Should validate required inputs", func(t *testing.T) {
+		tests := []struct {
+			player FFPlayPipePlayer
+		}{
+			{player: FFPlayPipePlayer{SampleRate: 44100, Duration: time.Second}},                                       // no wave
+			{player: FFPlayPipePlayer{Wave: &sound.MockWave{}, Duration: time.Second}},                                 // no sample rate
+			{player: FFPlayPipePlayer{Wave: &sound.MockWave{}, SampleRate: 44100}},                                     // no duration
+			{player: FFPlayPipePlayer{Wave: &sound.MockWave{}, SampleRate: 44100, Duration: time.Second, Format: "x"}}, // unknown format
+		}
+
+		for i, test := range tests {
+			err := test.player.Play()
+			if err == nil || errors.Is(err, ErrFFPlayCommand) {
+				t.Fatalf("want a validation error at index %d but got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("Should use the right arguments to launch ffplay", func(t *testing.T) {
+		got := strings.Join(newFFPlayPipeArgs(FormatS16LE, 48000, 2), " ")
+		for _, want := range []string{"-f s16le", "-ar 48000", "-ac 2", "-autoexit", "-i pipe:0"} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("want arguments that include %q but got %q", want, got)
+			}
+		}
+	})
+}
+
+func TestFFMpegExporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should validate required inputs", func(t *testing.T) {
+		err := FFMpegExporter{Wave: &sound.MockWave{}, SampleRate: 44100, Duration: time.Second}.Export()
+		if err == nil || errors.Is(err, ErrFFMpegCommand) {
+			t.Fatalf("want a validation error for a missing file path but got %v", err)
+		}
+	})
+
+	t.Run("Should use the right arguments to launch ffmpeg", func(t *testing.T) {
+		args := newFFMpegArgs(FormatF32LE, 44100, 1, []string{"-b:a", "192k"}, "out.mp3")
+		got := strings.Join(args, " ")
+		for _, want := range []string{"-y", "-f f32le", "-ar 44100", "-ac 1", "-i pipe:0 -b:a 192k out.mp3"} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("want arguments that include %q but got %q", want, got)
+			}
+		}
+		if args[len(args)-1] != "out.mp3" {
+			t.Fatalf("want the output file as the last argument but got %q", got)
+		}
+	})
+}
+
+func TestRunPipeCommand(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	newTestStream := func(t *testing.T, duration time.Duration) *Stream {
+		stream, err := NewStream(&sound.MockWave{}, 8000, 0, duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stream
+	}
+
+	t.Run("Should stream all frames to the command", func(t *testing.T) {
+		err := runPipeCommand(context.Background(), "sh", []string{"-c", "cat > /dev/null"}, newTestStream(t, time.Second), FormatS16LE)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Should include the standard error of the command in the error", func(t *testing.T) {
+		// the command reports how many bytes it received before failing
+		err := runPipeCommand(context.Background(), "sh", []string{"-c", "wc -c >&2; exit 3"}, newTestStream(t, time.Second), FormatS16LE)
+		if err == nil {
+			t.Fatal("want an error")
+		}
+		if want := strconv.Itoa(8000 * 2); !strings.Contains(err.Error(), want) {
+			t.Fatalf("want an error that includes the standard error output (%s) but got %q", want, err)
+		}
+	})
+
+	t.Run("Should stop when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := runPipeCommand(ctx, "sh", []string{"-c", "exec sleep 10"}, newTestStream(t, time.Hour), FormatF64LE)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want error %s but got %v", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("command should have been stopped early, but it took %s", elapsed)
+		}
+	})
+
+	t.Run("Should stop when the context is canceled while a child process holds the pipes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		// the shell is killed but not the sleep command, which inherited its standard input and error
+		start := time.Now()
+		err := runPipeCommand(ctx, "sh", []string{"-c", "sleep 5; cat > /dev/null"}, newTestStream(t, time.Hour), FormatF64LE)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want error %s but got %v", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("command should have been stopped early, but it took %s", elapsed)
+		}
+	})
+}
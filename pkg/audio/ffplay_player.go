@@ -15,11 +15,15 @@ import (
 // FFplayPlayer uses ffplay to play the provided frames.
 // It produces a .pcm file under the hood to encode the output sound wave.
 // This file can be saved by setting the SaveFile field to true.
+//
+// The StereoWave field can be set instead of the Wave field to play in stereo.
 type FFPlayPlayer struct {
 	Wave                sound.Wave
+	StereoWave          sound.StereoWave
 	SampleRate          int
 	DurationStartOffset time.Duration
 	Duration            time.Duration
+	Format              SampleFormat // format of the PCM file, defaults to DefaultSampleFormat
 	Filepath            string
 	SaveFile            bool
 	noExec              bool // to prevent ffplay command execution
@@ -34,15 +38,21 @@ func (p FFPlayPlayer) Play() error {
 	if p.Filepath == "" {
 		p.Filepath = strconv.Itoa(int(time.Now().Unix())) + ".pcm"
 	}
-	if p.Wave == nil {
+	if p.Wave == nil && p.StereoWave == nil {
 		return errors.New("no wave was provided")
 	}
 	if p.Duration <= 0 {
 		return fmt.Errorf("invalid duration: %s", p.Duration)
 	}
 
-	// get output frames
-	frames, err := Frames(p.Wave, p.SampleRate, p.DurationStartOffset, p.Duration)
+	// get output frames stream
+	var stream *Stream
+	var err error
+	if p.StereoWave != nil {
+		stream, err = NewStereoStream(p.StereoWave, p.SampleRate, p.DurationStartOffset, p.Duration)
+	} else {
+		stream, err = NewStream(p.Wave, p.SampleRate, p.DurationStartOffset, p.Duration)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get output frames from wave: %w", err)
 	}
@@ -52,33 +62,28 @@ func (p FFPlayPlayer) Play() error {
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
-	defer f.Close()
+	// the call to remove the file is deferred right away so that the file is removed even if something fails
+	defer func() {
+		f.Close()
+		// remove file after play if desired
+		if !p.SaveFile {
+			os.Remove(p.Filepath)
+		}
+	}()
 
 	// Encode PCM output to file
-	err = (&PCMEncoder{}).Encode(f, frames)
+	err = (&PCMEncoder{Format: p.Format}).EncodeStream(f, stream)
 	if err != nil {
 		return fmt.Errorf("failed to encode PCM pulses: %w", err)
 	}
 
-	// the call to remove the file is deferred (in case the command execution fails, then the the file wouldn't be removed)
-	defer func() error {
-		// remove file after play if desired
-		if !p.SaveFile {
-			err := os.Remove(p.Filepath)
-			if err != nil {
-				return fmt.Errorf("failed to delete file (%s): %w", p.Filepath, err)
-			}
-		}
-		return nil
-	}()
-
 	// allow caller to prevent command execution for testing for example.
 	if p.noExec {
 		return nil
 	}
 
 	// Read output file with ffplay (by launching ffplay from the CLI)
-	cmdstr := strings.Split(newFFPlayCommand(p.SampleRate, p.Filepath), " ")
+	cmdstr := strings.Split(newFFPlayCommand(p.Format.orDefault(), p.SampleRate, stream.NumChannels(), p.Filepath), " ")
 	err = exec.Command(cmdstr[0], cmdstr[1:]...).Run()
 	if err != nil {
 		return fmt.Errorf("failed to play PCM file using ffplay: %w: %s", ErrFFPlayCommand, err.Error())
@@ -87,10 +92,11 @@ func (p FFPlayPlayer) Play() error {
 }
 
 // newFFPlayCommand returns the command string used to play a PCM file with ffplay.
-func newFFPlayCommand(sampleRate int, filepath string) string {
+func newFFPlayCommand(format SampleFormat, sampleRate, numChannels int, filepath string) string {
 	return "ffplay" + " " +
-		"-f f64le" + " " +
+		"-f " + string(format) + " " +
 		"-ar " + strconv.Itoa(sampleRate) + " " +
+		"-ac " + strconv.Itoa(numChannels) + " " + // -ch_layout is only supported since ffmpeg 5.1
 		"-autoexit" + " " +
 		"-showmode 1" + " " +
 		filepath
@@ -76,7 +76,8 @@ func TestFFplayPlayer(t *testing.T) {
 	t.Run("Should use the right command to launch ffplay", func(t *testing.T) {
 		sampleRate := 34567
 		filename := "foo_command"
-		got := newFFPlayCommand(sampleRate, filename)
+		format := FormatS24BE
+		got := newFFPlayCommand(format, sampleRate, 2, filename)
 
 		// command start with ffplay
 		if !strings.HasPrefix(got, "ffplay"+" ") {
@@ -89,7 +90,7 @@ func TestFFplayPlayer(t *testing.T) {
 		}
 
 		// command should include the right frame format with -f flag
-		if !strings.Contains(got, " -f f64le ") {
+		if !strings.Contains(got, " -f "+string(format)+" ") {
 			t.Fatalf("Want a string that includes the frame format flag but got \"%s\"", got)
 		}
 
@@ -98,6 +99,11 @@ func TestFFplayPlayer(t *testing.T) {
 			t.Fatalf("Want a string that includes the sample rate flag but got: \"%s\"", got)
 		}
 
+		// command should include the number of channels with -ac flag
+		if !strings.Contains(got, " -ac 2 ") {
+			t.Fatalf("Want a string that includes the channels flag but got: \"%s\"", got)
+		}
+
 		// command should include -autoexit flag
 		if !strings.Contains(got, " -autoexit ") {
 			t.Fatalf("Want a string that includes the autoexit flag but got: \"%s\"", got)
@@ -109,6 +115,20 @@ func TestFFplayPlayer(t *testing.T) {
 		}
 	})
 
+	t.Run("Should accept a stereo wave instead of a mono wave", func(t *testing.T) {
+		player := &FFPlayPlayer{
+			StereoWave: sound.UpMix(&sound.MockWave{}),
+			SampleRate: 100,
+			Filepath:   "foo_stereo",
+			noExec:     true,
+			Duration:   time.Second,
+		}
+		err := player.Play()
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	})
+
 	t.Run("Should save file only if desired", func(t *testing.T) {
 		filepath := "foo_save_file"
 		player := &FFPlayPlayer{
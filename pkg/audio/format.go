@@ -0,0 +1,148 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// SampleFormat describes how a frame is represented in raw PCM data.
+// Format names are the same as the ones used by ffmpeg and ffplay (with the -f flag).
+type SampleFormat string
+
+// Supported sample formats.
+const (
+	FormatS16LE SampleFormat = "s16le" // signed 16-bit integer, little-endian
+	FormatS24LE SampleFormat = "s24le" // signed 24-bit integer, little-endian
+	FormatS32LE SampleFormat = "s32le" // signed 32-bit integer, little-endian
+	FormatF32LE SampleFormat = "f32le" // 32-bit float, little-endian
+	FormatF64LE SampleFormat = "f64le" // 64-bit float, little-endian
+	FormatS16BE SampleFormat = "s16be" // signed 16-bit integer, big-endian
+	FormatS24BE SampleFormat = "s24be" // signed 24-bit integer, big-endian
+	FormatS32BE SampleFormat = "s32be" // signed 32-bit integer, big-endian
+	FormatF32BE SampleFormat = "f32be" // 32-bit float, big-endian
+	FormatF64BE SampleFormat = "f64be" // 64-bit float, big-endian
+)
+
+// DefaultSampleFormat is the format used when no format is specified.
+const DefaultSampleFormat = FormatF64LE
+
+// sampleFormatSpec describes the binary layout of a sample format.
+type sampleFormatSpec struct {
+	size  int // in bytes
+	float bool
+	order binary.ByteOrder
+}
+
+var sampleFormatSpecs = map[SampleFormat]sampleFormatSpec{
+	FormatS16LE: {size: 2, order: binary.LittleEndian},
+	FormatS24LE: {size: 3, order: binary.LittleEndian},
+	FormatS32LE: {size: 4, order: binary.LittleEndian},
+	FormatF32LE: {size: 4, float: true, order: binary.LittleEndian},
+	FormatF64LE: {size: 8, float: true, order: binary.LittleEndian},
+	FormatS16BE: {size: 2, order: binary.BigEndian},
+	FormatS24BE: {size: 3, order: binary.BigEndian},
+	FormatS32BE: {size: 4, order: binary.BigEndian},
+	FormatF32BE: {size: 4, float: true, order: binary.BigEndian},
+	FormatF64BE: {size: 8, float: true, order: binary.BigEndian},
+}
+
+// orDefault returns the default sample format if the format is not defined.
+func (f SampleFormat) orDefault() SampleFormat {
+	if f == "" {
+		return DefaultSampleFormat
+	}
+	return f
+}
+
+// Size returns the number of bytes used by a single frame, or 0 if the format is not supported.
+func (f SampleFormat) Size() int {
+	return sampleFormatSpecs[f].size
+}
+
+// IsFloat reports whether frames are stored as floating point numbers.
+func (f SampleFormat) IsFloat() bool {
+	return sampleFormatSpecs[f].float
+}
+
+// validate returns an error if the format is not supported.
+func (f SampleFormat) validate() error {
+	if f.Size() == 0 {
+		return fmt.Errorf("unsupported sample format: %q", string(f))
+	}
+	return nil
+}
+
+// maxInt returns the largest integer value of an integer format.
+func (f SampleFormat) maxInt() float64 {
+	return float64(int64(1)<<(8*f.Size()-1) - 1)
+}
+
+// decode converts the bytes of a single frame to a frame value.
+// Integer frames are converted to values between -1 and 1.
+func (f SampleFormat) decode(b []byte) float64 {
+	spec := sampleFormatSpecs[f]
+	switch {
+	case spec.float && spec.size == 4:
+		return float64(math.Float32frombits(spec.order.Uint32(b)))
+	case spec.float && spec.size == 8:
+		return math.Float64frombits(spec.order.Uint64(b))
+	case spec.size == 2:
+		return float64(int16(spec.order.Uint16(b))) / (1 << 15)
+	case spec.size == 3:
+		var v uint32
+		if spec.order == binary.LittleEndian {
+			v = uint32(b[0])<<8 | uint32(b[1])<<16 | uint32(b[2])<<24
+		} else {
+			v = uint32(b[2])<<8 | uint32(b[1])<<16 | uint32(b[0])<<24
+		}
+		return float64(int32(v)>>8) / (1 << 23)
+	case spec.size == 4:
+		return float64(int32(spec.order.Uint32(b))) / (1 << 31)
+	}
+	return 0
+}
+
+// encode writes a single frame value to b.
+// Float frames are written as is. Integer frames are clipped to the -1 to 1 range (NaN values become 0)
+// and dither (expressed in least significant bits) is added before rounding.
+func (f SampleFormat) encode(b []byte, frame, dither float64) {
+	spec := sampleFormatSpecs[f]
+	if spec.float {
+		if spec.size == 4 {
+			spec.order.PutUint32(b, math.Float32bits(float32(frame)))
+		} else {
+			spec.order.PutUint64(b, math.Float64bits(frame))
+		}
+		return
+	}
+
+	frame = clip(frame)
+	max := f.maxInt()
+	v := int64(math.Max(-max, math.Min(max, math.Round(frame*max+dither))))
+	switch spec.size {
+	case 2:
+		spec.order.PutUint16(b, uint16(v))
+	case 3:
+		if spec.order == binary.LittleEndian {
+			b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+		} else {
+			b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+		}
+	case 4:
+		spec.order.PutUint32(b, uint32(v))
+	}
+}
+
+// clip restricts a frame value to the -1 to 1 range, NaN values become 0.
+func clip(frame float64) float64 {
+	switch {
+	case math.IsNaN(frame):
+		return 0
+	case frame > 1:
+		return 1
+	case frame < -1:
+		return -1
+	}
+	return frame
+}
@@ -0,0 +1,109 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSampleFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should have the right frame size", func(t *testing.T) {
+		tests := []struct {
+			format SampleFormat
+			want   int
+		}{
+			{format: FormatS16LE, want: 2},
+			{format: FormatS24LE, want: 3},
+			{format: FormatS32LE, want: 4},
+			{format: FormatF32LE, want: 4},
+			{format: FormatF64LE, want: 8},
+			{format: FormatS24BE, want: 3},
+			{format: FormatF64BE, want: 8},
+			{format: "unknown", want: 0},
+		}
+
+		for _, test := range tests {
+			if got := test.format.Size(); got != test.want {
+				t.Fatalf("%s: want size %d but got %d", test.format, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should decode frames", func(t *testing.T) {
+		tests := []struct {
+			format SampleFormat
+			data   []byte
+			want   float64
+		}{
+			{format: FormatS16LE, data: []byte{0x00, 0x80}, want: -1.0},
+			{format: FormatS24LE, data: []byte{0x00, 0x00, 0xC0}, want: -0.5},
+			{format: FormatS32LE, data: []byte{0x00, 0x00, 0x00, 0x40}, want: 0.5},
+			{format: FormatF32LE, data: []byte{0x00, 0x00, 0x80, 0x3E}, want: 0.25},
+			{format: FormatF64LE, data: []byte{0, 0, 0, 0, 0, 0, 0xE0, 0xBF}, want: -0.5},
+			{format: FormatS16BE, data: []byte{0x80, 0x00}, want: -1.0},
+			{format: FormatS24BE, data: []byte{0xC0, 0x00, 0x00}, want: -0.5},
+			{format: FormatS32BE, data: []byte{0x40, 0x00, 0x00, 0x00}, want: 0.5},
+			{format: FormatF32BE, data: []byte{0x3E, 0x80, 0x00, 0x00}, want: 0.25},
+			{format: FormatF64BE, data: []byte{0xBF, 0xE0, 0, 0, 0, 0, 0, 0}, want: -0.5},
+		}
+
+		for _, test := range tests {
+			got := test.format.decode(test.data)
+			if math.Abs(got-test.want) > 0.0001 {
+				t.Fatalf("%s: want %f but got %f", test.format, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should encode and decode frames in every format", func(t *testing.T) {
+		frames := []float64{0, 0.5, -0.5, 1, -1, 0.123456}
+		for format := range sampleFormatSpecs {
+			precision := 1e-7
+			if !format.IsFloat() {
+				precision = 1 / format.maxInt()
+			}
+
+			buf := make([]byte, format.Size())
+			for _, frame := range frames {
+				format.encode(buf, frame, 0)
+				got := format.decode(buf)
+				if math.Abs(got-frame) > precision {
+					t.Fatalf("%s: want %f but got %f", format, frame, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Should clip out of range values of integer formats", func(t *testing.T) {
+		for format := range sampleFormatSpecs {
+			if format.IsFloat() {
+				continue
+			}
+			buf := make([]byte, format.Size())
+			for _, frame := range []float64{2, -2, math.Inf(1), math.NaN()} {
+				format.encode(buf, frame, 0)
+				got := format.decode(buf)
+				if math.Abs(got) > 1 || math.IsNaN(got) {
+					t.Fatalf("%s: value %f should have been clipped but got %f", format, frame, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Should not clip float formats", func(t *testing.T) {
+		for _, format := range []SampleFormat{FormatF32LE, FormatF64BE} {
+			buf := make([]byte, format.Size())
+			for _, frame := range []float64{2, -3.5, math.Inf(1)} {
+				format.encode(buf, frame, 0)
+				if got := format.decode(buf); got != frame {
+					t.Fatalf("%s: want %f but got %f", format, frame, got)
+				}
+			}
+			format.encode(buf, math.NaN(), 0)
+			if got := format.decode(buf); !math.IsNaN(got) {
+				t.Fatalf("%s: want NaN but got %f", format, got)
+			}
+		}
+	})
+}
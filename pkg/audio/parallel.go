@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// parallelChunkSize is the number of frames (per channel) rendered by a worker at once.
+const parallelChunkSize = 8192
+
+// ParallelFrames is like Frames but it splits the rendering across several goroutines.
+// Frames are rendered by chunks on a pool of workers and reassembled in order.
+// If workers is zero or negative, one worker per CPU is used.
+//
+// Waves that must be rendered serially (see sound.SerialWave) are rendered on the calling goroutine.
+func ParallelFrames(wave sound.Wave, sampleRate int, startOffset, duration time.Duration, workers int) ([]float64, error) {
+	if wave == nil {
+		return nil, errors.New("wave is not defined, unable to get frames")
+	}
+	render := func(at time.Duration, dst []float64) error {
+		val, err := wave.Value(at)
+		dst[0] = val
+		return err
+	}
+	return parallelFrames(render, sound.IsSerial(wave), 1, sampleRate, startOffset, duration, workers)
+}
+
+// ParallelStereoFrames is like StereoFrames but it splits the rendering across several goroutines, see ParallelFrames.
+func ParallelStereoFrames(wave sound.StereoWave, sampleRate int, startOffset, duration time.Duration, workers int) ([]float64, error) {
+	if wave == nil {
+		return nil, errors.New("wave is not defined, unable to get frames")
+	}
+	render := func(at time.Duration, dst []float64) error {
+		left, right, err := wave.StereoValue(at)
+		dst[0], dst[1] = left, right
+		return err
+	}
+	return parallelFrames(render, sound.IsSerial(wave), 2, sampleRate, startOffset, duration, workers)
+}
+
+func parallelFrames(
+	render func(time.Duration, []float64) error,
+	serial bool,
+	numChannels, sampleRate int,
+	startOffset, duration time.Duration,
+	workers int,
+) ([]float64, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", sampleRate)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	length := FrameCount(sampleRate, duration)
+	if serial {
+		workers = 1
+	}
+
+	frames := make([]float64, length*numChannels)
+	renderChunk := func(from, to int) error {
+		for i := from; i < to; i++ {
+			err := render(sound.FrameTime(startOffset, i, sampleRate), frames[i*numChannels:(i+1)*numChannels])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if workers == 1 {
+		if err := renderChunk(0, length); err != nil {
+			return nil, err
+		}
+		return frames, nil
+	}
+
+	// each worker renders chunks directly in place, so frames are already in order when all workers are done
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	done := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for from := range chunks {
+				to := from + parallelChunkSize
+				if to > length {
+					to = length
+				}
+				if err := renderChunk(from, to); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						close(done)
+					})
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for from := 0; from < length; from += parallelChunkSize {
+		select {
+		case chunks <- from:
+		case <-done:
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return frames, nil
+}
@@ -0,0 +1,100 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestParallelFrames(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should validate required inputs", func(t *testing.T) {
+		if _, err := ParallelFrames(nil, 8000, 0, time.Second, 2); err == nil {
+			t.Fatal("want an error for a nil wave")
+		}
+		if _, err := ParallelFrames(&sound.MockWave{}, 0, 0, time.Second, 2); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+		if _, err := ParallelStereoFrames(nil, 8000, 0, time.Second, 2); err == nil {
+			t.Fatal("want an error for a nil stereo wave")
+		}
+	})
+
+	t.Run("Should render the same frames as Frames", func(t *testing.T) {
+		wave := sound.NewMergedWaves(
+			sound.NewSynthWave(&sound.Sine{}, 440),
+			sound.NewSynthWave(&sound.SawTooth{}, 110),
+		)
+		want, err := Frames(wave, 8000, time.Second, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, workers := range []int{0, 1, 3, 16} {
+			got, err := ParallelFrames(wave, 8000, time.Second, 5*time.Second, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%d workers: want %d frames but got %d", workers, len(want), len(got))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("%d workers: unexpected frame value at index %d, want %f but got %f", workers, i, want[i], got[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should render serial waves in order", func(t *testing.T) {
+		newNoise := func() sound.Wave {
+			return sound.NewSynthWave(sound.NewRandomWideBandNoiseSynthesizer(42), 0)
+		}
+		want, err := Frames(newNoise(), 8000, 0, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ParallelFrames(newNoise(), 8000, 0, 5*time.Second, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got[i])
+			}
+		}
+	})
+
+	t.Run("Should render interleaved stereo frames", func(t *testing.T) {
+		wave := sound.NewPannedWave(sound.NewSynthWave(&sound.Square{}, 3), 0.5)
+		want, err := StereoFrames(wave, 8000, 0, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ParallelStereoFrames(wave, 8000, 0, 5*time.Second, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got[i])
+			}
+		}
+	})
+
+	t.Run("Should return wave errors", func(t *testing.T) {
+		wave := withDeadline(&sound.MockWave{}, 3*time.Second)
+		_, err := ParallelFrames(wave, 8000, 0, 5*time.Second, 4)
+		if !errors.Is(err, sound.ErrEndOfWave) {
+			t.Fatalf("want error %s but got %v", sound.ErrEndOfWave, err)
+		}
+	})
+}
@@ -1,13 +1,23 @@
 package audio
 
 import (
-	"encoding/binary"
+	"bufio"
 	"errors"
+	"fmt"
 	"io"
-	"math"
+	"math/rand"
 )
 
-type PCMEncoder struct{}
+// PCMEncoder encodes frames as raw PCM data (with no header).
+// Raw PCM data doesn't describe its number of channels, frames of multichannel audio are simply written interleaved.
+type PCMEncoder struct {
+	Format SampleFormat // defaults to DefaultSampleFormat
+
+	// Dither enables TPDF (triangular probability density function) dithering
+	// when encoding to an integer format, this masks the quantization distortion with a low level noise.
+	Dither     bool
+	DitherSeed int64 // seed of the random noise used for dithering
+}
 
 // EncodePCM encodes a sound's PCM representation to an io.Writer
 func (e *PCMEncoder) Encode(w io.Writer, frames []float64) error {
@@ -17,25 +27,137 @@ func (e *PCMEncoder) Encode(w io.Writer, frames []float64) error {
 	if w == nil {
 		return errors.New("no io.Writer was provided")
 	}
+	format := e.Format.orDefault()
+	if err := format.validate(); err != nil {
+		return err
+	}
 
 	// encode each pulse to writer
-	for _, pulse := range frames {
-		var buf [8]byte
-		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(pulse))
-		_, err := w.Write(buf[:])
+	bw := bufio.NewWriter(w)
+	writeFrames(bw, format, frames, e.ditherSource(format), false)
+
+	return bw.Flush()
+}
+
+// EncodeStream encodes the frames of a stream to an io.Writer, block by block.
+func (e *PCMEncoder) EncodeStream(w io.Writer, s *Stream) error {
+	if s == nil || s.Len() == 0 {
+		return errors.New("no frames were provided")
+	}
+	if w == nil {
+		return errors.New("no io.Writer was provided")
+	}
+	format := e.Format.orDefault()
+	if err := format.validate(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	err := writeStream(bw, format, s, e.ditherSource(format), false)
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ditherSource returns the random source used for dithering, or nil if frames should not be dithered.
+func (e *PCMEncoder) ditherSource(format SampleFormat) *rand.Rand {
+	if !e.Dither || format.IsFloat() {
+		return nil
+	}
+	return rand.New(rand.NewSource(e.DitherSeed))
+}
+
+// writeFrames encodes frames in the given format.
+// If r is not nil, it is used to add TPDF dither to the frames.
+// Integer frames are always clipped, clipFloats clips float frames too.
+func writeFrames(bw *bufio.Writer, format SampleFormat, frames []float64, r *rand.Rand, clipFloats bool) {
+	buf := make([]byte, format.Size())
+	for _, frame := range frames {
+		dither := 0.0
+		if r != nil {
+			dither = r.Float64() - r.Float64()
+		}
+		if clipFloats {
+			frame = clip(frame)
+		}
+		format.encode(buf, frame, dither)
+		bw.Write(buf)
+	}
+}
+
+// writeStream encodes all the frames of a stream in the given format, block by block.
+func writeStream(bw *bufio.Writer, format SampleFormat, s *Stream, r *rand.Rand, clipFloats bool) error {
+	block := make([]float64, StreamBlockSize*s.NumChannels())
+	for {
+		n, err := s.Read(block)
+		writeFrames(bw, format, block[:n], r, clipFloats)
+		// flush every block to stop rendering as soon as the writer fails
+		if flushErr := bw.Flush(); flushErr != nil {
+			return flushErr
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to render frames: %w", err)
 		}
 	}
+}
 
-	return nil
+// PCMDecoder decodes raw PCM data (with no header) to a sample.
+// The data must contain frames in the given format, interleaved when there is more than one channel.
+type PCMDecoder struct {
+	Format      SampleFormat // defaults to DefaultSampleFormat (the format produced by PCMEncoder)
+	SampleRate  int          // number of frames per second, must be positive
+	NumChannels int          // number of interleaved channels, defaults to 1 (mono)
 }
 
-type PCMDecoder struct{}
+// ErrTruncatedFrame means that the data ends in the middle of a frame.
+var ErrTruncatedFrame = errors.New("truncated frame")
 
-func (e *PCMDecoder) Decode(r io.Reader) (*Sample, error) {
+func (d *PCMDecoder) Decode(r io.Reader) (*Sample, error) {
 	if r == nil {
 		return nil, errors.New("no io.Reader was provided")
 	}
-	return nil, nil
+	if d.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate must be positive", d.SampleRate)
+	}
+	format := d.Format.orDefault()
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+	numChannels := d.NumChannels
+	if numChannels == 0 {
+		numChannels = 1
+	}
+	if numChannels < 0 {
+		return nil, fmt.Errorf("invalid number of channels: %d", numChannels)
+	}
+
+	frameSize := format.Size()
+	frames := []float64{}
+	buf := make([]byte, 4096*frameSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		for i := 0; i+frameSize <= n; i += frameSize {
+			frames = append(frames, format.decode(buf[i:]))
+		}
+		if n%frameSize != 0 {
+			return nil, fmt.Errorf("%w: %d trailing bytes", ErrTruncatedFrame, n%frameSize)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PCM data: %w", err)
+		}
+	}
+
+	if len(frames)%numChannels != 0 {
+		return nil, fmt.Errorf("%w: %d frames can't be split in %d channels", ErrTruncatedFrame, len(frames), numChannels)
+	}
+
+	return NewMultiChannelSample(frames, d.SampleRate, numChannels), nil
 }
@@ -2,7 +2,9 @@ package audio
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"math"
 	"testing"
 )
 
@@ -38,6 +40,65 @@ func TestPCMEncoder(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Should encode frames in the configured format", func(t *testing.T) {
+		frames := []float64{0.5, -0.5}
+		tests := []struct {
+			format SampleFormat
+			want   []byte
+		}{
+			{format: FormatS16LE, want: []byte{0x00, 0x40, 0x00, 0xC0}},
+			{format: FormatS16BE, want: []byte{0x40, 0x00, 0xC0, 0x00}},
+			{format: FormatF32LE, want: []byte{0x00, 0x00, 0x00, 0x3F, 0x00, 0x00, 0x00, 0xBF}},
+		}
+
+		for _, test := range tests {
+			buf := &bytes.Buffer{}
+			err := (&PCMEncoder{Format: test.format}).Encode(buf, frames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), test.want) {
+				t.Fatalf("%s: want %x but got %x", test.format, test.want, buf.Bytes())
+			}
+		}
+	})
+
+	t.Run("Should dither integer formats deterministically", func(t *testing.T) {
+		frames := make([]float64, 1000)
+		for i := range frames {
+			frames[i] = 0.3
+		}
+		encode := func(encoder *PCMEncoder) []byte {
+			buf := &bytes.Buffer{}
+			if err := encoder.Encode(buf, frames); err != nil {
+				t.Fatal(err)
+			}
+			return buf.Bytes()
+		}
+
+		plain := encode(&PCMEncoder{Format: FormatS16LE})
+		dithered := encode(&PCMEncoder{Format: FormatS16LE, Dither: true, DitherSeed: 7})
+		if bytes.Equal(plain, dithered) {
+			t.Fatal("dithered output should be different from the plain output")
+		}
+		if !bytes.Equal(dithered, encode(&PCMEncoder{Format: FormatS16LE, Dither: true, DitherSeed: 7})) {
+			t.Fatal("dithered output should be the same for the same seed")
+		}
+
+		// dither noise should stay within one least significant bit
+		for i := 0; i < len(dithered); i += 2 {
+			got := FormatS16LE.decode(dithered[i:])
+			if math.Abs(got-0.3) > 1.5/FormatS16LE.maxInt() {
+				t.Fatalf("dithered frame %d is too far from the original value: %f", i/2, got)
+			}
+		}
+
+		// float formats are not dithered
+		if !bytes.Equal(encode(&PCMEncoder{Format: FormatF32LE}), encode(&PCMEncoder{Format: FormatF32LE, Dither: true})) {
+			t.Fatal("float formats should not be dithered")
+		}
+	})
 }
 
 func TestPCMDecoder(t *testing.T) {
@@ -48,24 +109,100 @@ func TestPCMDecoder(t *testing.T) {
 	})
 
 	t.Run("Should validate required inputs", func(t *testing.T) {
-		decoder := &PCMDecoder{}
+		validDecoder := &PCMDecoder{SampleRate: 44100}
 		validReader := bytes.NewBuffer([]byte{})
 		var invalidReader io.Reader = nil
 
 		tests := []struct {
+			decoder *PCMDecoder
 			reader  io.Reader
 			wantErr bool
 		}{
-			{reader: validReader, wantErr: false},
-			{reader: invalidReader, wantErr: true},
+			{decoder: validDecoder, reader: validReader, wantErr: false},
+			{decoder: validDecoder, reader: invalidReader, wantErr: true},
+			{decoder: &PCMDecoder{}, reader: validReader, wantErr: true},                                // no sample rate
+			{decoder: &PCMDecoder{SampleRate: 44100, Format: "u7"}, reader: validReader, wantErr: true}, // unknown format
 		}
 
 		for i, test := range tests {
-			_, err := decoder.Decode(test.reader)
+			_, err := test.decoder.Decode(test.reader)
 			if (err != nil) != test.wantErr {
 				// ignore command error
 				t.Fatalf("unexpected error at index %d, wantErr is %v but got %v", i, test.wantErr, err)
 			}
 		}
 	})
+
+	t.Run("Should decode frames encoded with PCMEncoder", func(t *testing.T) {
+		frames := []float64{0.0, 0.25, -1.0, 1.0}
+		buf := &bytes.Buffer{}
+		err := (&PCMEncoder{}).Encode(buf, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sample, err := (&PCMDecoder{SampleRate: 8000}).Decode(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sample.sampleRate != 8000 {
+			t.Fatalf("want sample rate 8000 but got %d", sample.sampleRate)
+		}
+		if len(sample.frames) != len(frames) {
+			t.Fatalf("want %d frames but got %d", len(frames), len(sample.frames))
+		}
+		for i, want := range frames {
+			if sample.frames[i] != want {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want, sample.frames[i])
+			}
+		}
+	})
+
+	t.Run("Should decode frames in the configured format", func(t *testing.T) {
+		data := []byte{0x00, 0x40, 0x00, 0xC0, 0xFF, 0x7F} // 0.5, -0.5, ~1.0 as s16le
+		want := []float64{0.5, -0.5, 1.0}
+
+		sample, err := (&PCMDecoder{SampleRate: 8000, Format: FormatS16LE}).Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sample.frames) != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), len(sample.frames))
+		}
+		for i := range want {
+			if math.Abs(sample.frames[i]-want[i]) > 0.0001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], sample.frames[i])
+			}
+		}
+	})
+
+	t.Run("Should decode interleaved channels", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := (&PCMEncoder{}).Encode(buf, []float64{1, -1, 0.5, -0.5})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sample, err := (&PCMDecoder{SampleRate: 8000, NumChannels: 2}).Decode(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sample.NumChannels() != 2 || sample.Len() != 2 {
+			t.Fatalf("want 2 frames in 2 channels but got %d frames in %d channels", sample.Len(), sample.NumChannels())
+		}
+	})
+
+	t.Run("Should return an error for truncated frames", func(t *testing.T) {
+		data := make([]byte, 8*3+5) // 3 f64le frames and 5 extra bytes
+		_, err := (&PCMDecoder{SampleRate: 8000}).Decode(bytes.NewReader(data))
+		if !errors.Is(err, ErrTruncatedFrame) {
+			t.Fatalf("want error %s but got %v", ErrTruncatedFrame, err)
+		}
+
+		data = make([]byte, 8*3) // 3 f64le frames can't be split in 2 channels
+		_, err = (&PCMDecoder{SampleRate: 8000, NumChannels: 2}).Decode(bytes.NewReader(data))
+		if !errors.Is(err, ErrTruncatedFrame) {
+			t.Fatalf("want error %s but got %v", ErrTruncatedFrame, err)
+		}
+	})
 }
@@ -0,0 +1,109 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ejuju/musigo/pkg/maths"
+)
+
+// Interpolation defines how the value of a sample is computed between two of its frames.
+type Interpolation int
+
+const (
+	InterpolationNone   Interpolation = iota // value of the last frame (default)
+	InterpolationLinear                      // straight line between the two surrounding frames
+	InterpolationCubic                       // cubic Hermite spline using the four surrounding frames
+	InterpolationSinc                        // windowed sinc using the 2*SincInterpolationWidth surrounding frames
+)
+
+// SincInterpolationWidth is the number of frames used on each side of the position when using InterpolationSinc.
+const SincInterpolationWidth = 16
+
+// ResampleWidth is the number of input frames used on each side of each output frame by Resample
+// (multiplied by the conversion ratio when reducing the sample rate).
+const ResampleWidth = 32
+
+// WithInterpolation returns a copy of the sample that uses the given interpolation.
+// Frames are shared with the original sample.
+func (s *Sample) WithInterpolation(interpolation Interpolation) *Sample {
+	out := *s
+	out.interpolation = interpolation
+	return &out
+}
+
+// Interpolation returns the interpolation used by the sample.
+func (s *Sample) Interpolation() Interpolation {
+	return s.interpolation
+}
+
+// interpolate returns the value of a channel at the given frame index and progress towards the next frame.
+func (s *Sample) interpolate(index int, progress float64, channel int) float64 {
+	switch s.interpolation {
+	case InterpolationLinear:
+		y1, y2 := s.frame(index, channel), s.frame(index+1, channel)
+		return y1 + (y2-y1)*progress
+	case InterpolationCubic:
+		return maths.CubicHermiteInterpolation(
+			s.frame(index-1, channel),
+			s.frame(index, channel),
+			s.frame(index+1, channel),
+			s.frame(index+2, channel),
+			progress,
+		)
+	case InterpolationSinc:
+		if progress == 0 {
+			return s.frame(index, channel)
+		}
+		sum := 0.0
+		for k := index - SincInterpolationWidth + 1; k <= index+SincInterpolationWidth; k++ {
+			x := float64(index-k) + progress
+			sum += s.frame(k, channel) * maths.Sinc(x) * maths.BlackmanWindow(x/SincInterpolationWidth)
+		}
+		return sum
+	default:
+		return s.frame(index, channel)
+	}
+}
+
+// Resample converts the frames of the sample to a new sample rate using windowed sinc interpolation.
+// When the sample rate is reduced, frequencies above the new Nyquist frequency are filtered out to avoid aliasing.
+// The returned sample keeps the name, channels and interpolation of the input sample.
+func Resample(s *Sample, sampleRate int) (*Sample, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate must be positive", sampleRate)
+	}
+	if s.sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid input sample rate: %d, sample rate must be positive", s.sampleRate)
+	}
+
+	out := *s
+	out.sampleRate = sampleRate
+	if sampleRate == s.sampleRate {
+		out.frames = append([]float64(nil), s.frames...)
+		return &out, nil
+	}
+
+	ratio := float64(sampleRate) / float64(s.sampleRate)
+	cutoff := math.Min(1, ratio) // relative to the input Nyquist frequency
+	width := ResampleWidth / cutoff
+
+	inLen := int64(s.Len())
+	outLen := int((inLen*int64(sampleRate) + int64(s.sampleRate) - 1) / int64(s.sampleRate))
+	out.frames = make([]float64, outLen*s.numChannels)
+
+	for i := 0; i < outLen; i++ {
+		pos := float64(int64(i)*int64(s.sampleRate)) / float64(sampleRate) // position in input frames
+		first := int(math.Floor(pos-width)) + 1
+		last := int(math.Floor(pos + width))
+		for c := 0; c < s.numChannels; c++ {
+			sum := 0.0
+			for k := first; k <= last; k++ {
+				x := pos - float64(k)
+				sum += s.frame(k, c) * cutoff * maths.Sinc(cutoff*x) * maths.BlackmanWindow(x/width)
+			}
+			out.frames[i*s.numChannels+c] = sum
+		}
+	}
+	return &out, nil
+}
@@ -0,0 +1,175 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestSampleInterpolation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should return the exact frames at frame times", func(t *testing.T) {
+		frames := []float64{0.1, -0.4, 0.9, 0.3, -0.2, 0.7}
+		sampleRate := 44100
+		for _, interpolation := range []Interpolation{InterpolationNone, InterpolationLinear, InterpolationCubic, InterpolationSinc} {
+			sample := NewSample(frames, sampleRate).WithInterpolation(interpolation)
+			for i, frame := range frames {
+				got, err := sample.Value(sound.FrameTime(0, i, sampleRate))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if math.Abs(got-frame) > 0.0000001 {
+					t.Fatalf("interpolation %d: unexpected frame value at index %d, want %f but got %f", interpolation, i, frame, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Should interpolate between frames", func(t *testing.T) {
+		frames := []float64{0, 1, 1, 0}
+		halfway := 1500 * time.Millisecond
+
+		tests := []struct {
+			interpolation Interpolation
+			want          float64
+		}{
+			{interpolation: InterpolationNone, want: 1},
+			{interpolation: InterpolationLinear, want: 1},
+			{interpolation: InterpolationCubic, want: 1.125},
+		}
+
+		for _, test := range tests {
+			got, err := NewSample(frames, 1).WithInterpolation(test.interpolation).Value(halfway)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-test.want) > 0.0000001 {
+				t.Fatalf("interpolation %d: want %f but got %f", test.interpolation, test.want, got)
+			}
+		}
+
+		got, _ := NewSample(frames, 1).WithInterpolation(InterpolationLinear).Value(500 * time.Millisecond)
+		if got != 0.5 {
+			t.Fatalf("want 0.5 but got %f", got)
+		}
+	})
+
+	t.Run("Should interpolate each channel of a multichannel sample", func(t *testing.T) {
+		sample := NewMultiChannelSample([]float64{0, 1, 1, -1}, 1, 2).WithInterpolation(InterpolationLinear)
+		left, right, err := sample.StereoValue(500 * time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left != 0.5 || right != 0 {
+			t.Fatalf("want 0.5 and 0 but got %f and %f", left, right)
+		}
+	})
+
+	t.Run("Should not modify the original sample", func(t *testing.T) {
+		sample := NewSample([]float64{0, 1}, 1)
+		_ = sample.WithInterpolation(InterpolationLinear)
+		if sample.Interpolation() != InterpolationNone {
+			t.Fatalf("want the original interpolation to be kept but got %d", sample.Interpolation())
+		}
+	})
+
+	t.Run("Should reconstruct a band-limited signal with sinc interpolation", func(t *testing.T) {
+		sampleRate := 8000
+		frames := make([]float64, sampleRate)
+		for i := range frames {
+			frames[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / float64(sampleRate))
+		}
+		sample := NewSample(frames, sampleRate).WithInterpolation(InterpolationSinc)
+
+		// check values between frames, away from the edges
+		for _, at := range []time.Duration{100*time.Millisecond + 60*time.Microsecond, 500*time.Millisecond + 33*time.Microsecond} {
+			got, _ := sample.Value(at)
+			want := math.Sin(2 * math.Pi * 1000 * at.Seconds())
+			if math.Abs(got-want) > 0.001 {
+				t.Fatalf("at %s: want %f but got %f", at, want, got)
+			}
+		}
+	})
+}
+
+func TestResample(t *testing.T) {
+	t.Parallel()
+
+	newSine := func(freq float64, sampleRate int, duration time.Duration) *Sample {
+		frames := make([]float64, FrameCount(sampleRate, duration))
+		for i := range frames {
+			frames[i] = math.Sin(2 * math.Pi * freq * float64(i) / float64(sampleRate))
+		}
+		return NewSample(frames, sampleRate)
+	}
+
+	// rms returns the root mean square of the frames, ignoring the edges.
+	rms := func(frames []float64) float64 {
+		edge := len(frames) / 10
+		sum := 0.0
+		for _, v := range frames[edge : len(frames)-edge] {
+			sum += v * v
+		}
+		return math.Sqrt(sum / float64(len(frames)-2*edge))
+	}
+
+	t.Run("Should validate the sample rate", func(t *testing.T) {
+		if _, err := Resample(NewSample([]float64{0}, 44100), 0); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+	})
+
+	t.Run("Should produce the right number of frames and channels", func(t *testing.T) {
+		tests := []struct {
+			in, out int
+			want    int
+		}{
+			{in: 48000, out: 44100, want: 44100},
+			{in: 22050, out: 44100, want: 44100},
+			{in: 44100, out: 44100, want: 44100},
+		}
+
+		for _, test := range tests {
+			sample := NewMultiChannelSample(make([]float64, 2*test.in), test.in, 2)
+			got, err := Resample(sample, test.out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.SampleRate() != test.out || got.NumChannels() != 2 || got.Len() != test.want {
+				t.Fatalf("%d to %d Hz: unexpected sample: %d Hz, %d channels, %d frames",
+					test.in, test.out, got.SampleRate(), got.NumChannels(), got.Len())
+			}
+		}
+	})
+
+	t.Run("Should keep frequencies below the new Nyquist frequency", func(t *testing.T) {
+		for _, rates := range [][2]int{{48000, 44100}, {22050, 44100}, {44100, 8000}} {
+			got, err := Resample(newSine(1000, rates[0], time.Second), rates[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := newSine(1000, rates[1], time.Second)
+			for i := got.Len() / 10; i < got.Len()*9/10; i++ {
+				if math.Abs(got.frames[i]-want.frames[i]) > 0.005 {
+					t.Fatalf("%d to %d Hz: unexpected frame value at index %d, want %f but got %f",
+						rates[0], rates[1], i, want.frames[i], got.frames[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should filter out frequencies above the new Nyquist frequency", func(t *testing.T) {
+		sample := newSine(6000, 48000, time.Second)
+		got, err := Resample(sample, 8000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// without anti-aliasing, the tone would be folded back to 2000 Hz at full level
+		if level := rms(got.frames); level > 0.01 {
+			t.Fatalf("want the tone to be filtered out but got a RMS level of %f", level)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package audio
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/ejuju/musigo/pkg/sound"
@@ -10,23 +11,116 @@ import (
 // Sample represents an audio sample
 // (for ex: a drum sound that you want to use in your song)
 type Sample struct {
-	name       string
-	sampleRate int // how many frames per second
-	frames     []float64
+	name        string
+	sampleRate  int // how many frames per second
+	numChannels int
+	frames      []float64 // interleaved when there is more than one channel
+
+	interpolation Interpolation // how values between two frames are computed
 }
 
-// NewSample creates a new sample using the input frames and sample rate.
+// NewSample creates a new mono sample using the input frames and sample rate.
 func NewSample(frames []float64, sampleRate int) *Sample {
-	return &Sample{frames: frames, sampleRate: sampleRate}
+	return &Sample{frames: frames, sampleRate: sampleRate, numChannels: 1}
+}
+
+// NewMultiChannelSample creates a new sample using interleaved input frames.
+func NewMultiChannelSample(frames []float64, sampleRate, numChannels int) *Sample {
+	if numChannels <= 0 {
+		numChannels = 1
+	}
+	return &Sample{frames: frames, sampleRate: sampleRate, numChannels: numChannels}
+}
+
+// Name returns the name of the sample.
+func (s *Sample) Name() string {
+	return s.name
+}
+
+// WithName returns a copy of the sample with the given name.
+// Frames are shared with the original sample.
+func (s *Sample) WithName(name string) *Sample {
+	out := *s
+	out.name = name
+	return &out
+}
+
+// SampleRate returns the number of frames per second of the sample.
+func (s *Sample) SampleRate() int {
+	return s.sampleRate
+}
+
+// NumChannels returns the number of channels of the sample.
+func (s *Sample) NumChannels() int {
+	return s.numChannels
+}
+
+// Len returns the number of frames per channel.
+func (s *Sample) Len() int {
+	return len(s.frames) / s.numChannels
+}
+
+// Channel returns the frames of a single channel.
+func (s *Sample) Channel(index int) []float64 {
+	out := make([]float64, s.Len())
+	for i := range out {
+		out[i] = s.frames[i*s.numChannels+index]
+	}
+	return out
 }
 
 // Value returns the frame value for the at the input time duration.
+// The channels of a multichannel sample are mixed down to mono.
 func (s *Sample) Value(at time.Duration) (float64, error) {
-	frameIndex := at / (time.Second / time.Duration(s.sampleRate))
+	index, progress, err := s.position(at)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for c := 0; c < s.numChannels; c++ {
+		sum += s.interpolate(index, progress, c)
+	}
+	return sum / float64(s.numChannels), nil
+}
 
-	if int(frameIndex) >= len(s.frames) {
-		return 0, fmt.Errorf("failed to get frame index for sample \"%s\": %w", s.name, sound.ErrEndOfWave)
+// StereoValue returns the left and right frame values at the input time duration.
+// Mono samples play the same value in both channels and only the first two channels of other samples are used.
+func (s *Sample) StereoValue(at time.Duration) (float64, float64, error) {
+	index, progress, err := s.position(at)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return s.frames[frameIndex], nil
+	left := s.interpolate(index, progress, 0)
+	if s.numChannels == 1 {
+		return left, left, nil
+	}
+	return left, s.interpolate(index, progress, 1), nil
+}
+
+// position returns the index of the frame playing at the input time
+// and the progress (from 0 to 1) towards the next frame.
+func (s *Sample) position(at time.Duration) (int, float64, error) {
+	index := sound.FrameIndex(at, s.sampleRate)
+	if index < 0 || index >= s.Len() {
+		return 0, 0, fmt.Errorf("failed to get frame index for sample \"%s\": %w", s.name, sound.ErrEndOfWave)
+	}
+
+	progress := float64(at-sound.FrameTime(0, index, s.sampleRate)) * float64(s.sampleRate) / float64(time.Second)
+	if progress < 0 {
+		progress = 0
+	} else if progress >= 1 {
+		progress = math.Nextafter(1, 0)
+	}
+	return index, progress, nil
+}
+
+// frame returns the value of a channel at the given frame index,
+// frames outside of the sample are silent.
+func (s *Sample) frame(index, channel int) float64 {
+	if index < 0 || index >= s.Len() {
+		return 0
+	}
+	return s.frames[index*s.numChannels+channel]
 }
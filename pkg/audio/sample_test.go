@@ -15,6 +15,10 @@ func TestSample(t *testing.T) {
 		var _ sound.Wave = &Sample{}
 	})
 
+	t.Run("Should implement sound.StereoWave", func(t *testing.T) {
+		var _ sound.StereoWave = &Sample{}
+	})
+
 	t.Run("Should return the right frame value for a given time", func(t *testing.T) {
 		frames := []float64{1.0, 0.0, -1.0}
 		sampleRate := 44100
@@ -29,6 +33,33 @@ func TestSample(t *testing.T) {
 		}
 	})
 
+	t.Run("Should return the right frame at sample rates that don't divide a second", func(t *testing.T) {
+		sampleRate := 44100
+		frames := make([]float64, sampleRate)
+		for i := range frames {
+			frames[i] = float64(i)
+		}
+		sample := NewSample(frames, sampleRate)
+
+		for _, i := range []int{0, 1, 1000, 44098, 44099} {
+			got, err := sample.Value(sound.FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != float64(i) {
+				t.Fatalf("want frame %d but got frame %d", i, int(got))
+			}
+		}
+	})
+
+	t.Run("Should return a renamed copy", func(t *testing.T) {
+		sample := NewSample([]float64{0}, 1)
+		renamed := sample.WithName("kick")
+		if renamed.Name() != "kick" || sample.Name() != "" {
+			t.Fatalf("want names \"kick\" and \"\" but got %q and %q", renamed.Name(), sample.Name())
+		}
+	})
+
 	t.Run("Should return ErrEndOfWave when there's no frame left", func(t *testing.T) {
 		frames := []float64{1.0, 0.0, -1.0}
 		sampleRate := 1
@@ -40,4 +71,33 @@ func TestSample(t *testing.T) {
 			t.Fatalf("unexpected error value, want %s but got %s", sound.ErrEndOfWave, err)
 		}
 	})
+
+	t.Run("Should play multichannel samples in mono and stereo", func(t *testing.T) {
+		frames := []float64{1.0, 0.0, -1.0, 0.5}
+		sample := NewMultiChannelSample(frames, 1, 2)
+
+		if sample.Len() != 2 {
+			t.Fatalf("want 2 frames per channel but got %d", sample.Len())
+		}
+
+		mono, _ := sample.Value(time.Second)
+		if mono != -0.25 {
+			t.Fatalf("want the channels to be mixed down to -0.25 but got %f", mono)
+		}
+
+		left, right, _ := sample.StereoValue(time.Second)
+		if left != -1.0 || right != 0.5 {
+			t.Fatalf("want (-1, 0.5) but got (%f, %f)", left, right)
+		}
+
+		left, right, _ = NewSample(frames, 1).StereoValue(time.Second)
+		if left != 0.0 || right != 0.0 {
+			t.Fatalf("mono sample should play the same frame in both channels, got (%f, %f)", left, right)
+		}
+
+		_, _, err := sample.StereoValue(2 * time.Second)
+		if !errors.Is(err, sound.ErrEndOfWave) {
+			t.Fatalf("unexpected error value, want %s but got %s", sound.ErrEndOfWave, err)
+		}
+	})
 }
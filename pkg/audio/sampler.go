@@ -0,0 +1,97 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// LoopMode defines what a sampler does when it reaches the end of its sample.
+type LoopMode int
+
+const (
+	LoopOneShot LoopMode = iota // play the sample once (default)
+	LoopForward                 // repeat the part between the loop start and end until the note ends
+)
+
+// Sampler plays a sample as an instrument, it is an implementation of the sound.Synthesizer type.
+// The sample is repitched (and sped up or slowed down accordingly) by the ratio
+// between the requested frequency and the frequency of its root note.
+//
+// You must call NewSampler to create a sampler.
+type Sampler struct {
+	sample    *Sample
+	root      music.NoteNumber
+	loopMode  LoopMode
+	loopStart time.Duration
+	loopEnd   time.Duration
+}
+
+// NewSampler creates a one-shot sampler that plays the sample at its recorded pitch for the root note.
+func NewSampler(sample *Sample, root music.NoteNumber) Sampler {
+	return Sampler{sample: sample, root: root}
+}
+
+// Loop returns a copy of the sampler that repeats the part of the sample between start and end.
+// If end is 0 or after the end of the sample, the loop ends with the sample.
+func (s Sampler) Loop(start, end time.Duration) Sampler {
+	s.loopMode = LoopForward
+	s.loopStart = start
+	s.loopEnd = end
+	return s
+}
+
+// OneShot returns a copy of the sampler that plays its sample once.
+func (s Sampler) OneShot() Sampler {
+	s.loopMode = LoopOneShot
+	return s
+}
+
+// Root returns the note at which the sample plays at its recorded pitch.
+func (s Sampler) Root() music.NoteNumber {
+	return s.root
+}
+
+func (s Sampler) Synthesize(freq float64, at time.Duration) (float64, error) {
+	if s.sample == nil {
+		return 0, fmt.Errorf("no sample was provided: %w", sound.ErrEndOfWave)
+	}
+	return s.sample.Value(s.position(at, freq/s.root.Hz()))
+}
+
+// position returns the time in the sample to play at the given time, for the given playback speed ratio.
+func (s Sampler) position(at time.Duration, ratio float64) time.Duration {
+	pos := time.Duration(float64(at) * ratio)
+	// the loop can't be computed with an invalid sample rate, Sample.Value then returns an error
+	if s.loopMode != LoopForward || s.sample.sampleRate <= 0 {
+		return pos
+	}
+
+	length := sound.FrameTime(0, s.sample.Len(), s.sample.sampleRate)
+	end := s.loopEnd
+	if end <= 0 || end > length {
+		end = length
+	}
+	start := s.loopStart
+	if start < 0 {
+		start = 0
+	}
+	if start >= end || pos < end {
+		return pos
+	}
+	return start + time.Duration(math.Mod(float64(pos-start), float64(end-start)))
+}
+
+// Note returns a wave that plays the sample at the pitch of the given note.
+func (s Sampler) Note(note music.NoteNumber) sound.SynthWave {
+	return sound.NewSynthWave(s, note.Hz())
+}
+
+// Semitones returns a wave that plays the sample shifted by the given number of semitones.
+// For example, 12 plays the sample one octave higher and twice as fast.
+func (s Sampler) Semitones(semitones float64) sound.SynthWave {
+	return sound.NewSynthWave(s, music.RelativeFrequency(s.root.Hz(), semitones))
+}
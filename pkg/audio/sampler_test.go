@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	// 10 frames per second whose values are their index
+	frames := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	sample := NewSample(frames, 10)
+
+	t.Run("Should implement sound.Synthesizer", func(t *testing.T) {
+		var _ sound.Synthesizer = Sampler{}
+	})
+
+	t.Run("Should repitch the sample relative to its root note", func(t *testing.T) {
+		sampler := NewSampler(sample, music.NoteA4)
+
+		tests := []struct {
+			wave sound.Wave
+			at   time.Duration
+			want float64
+		}{
+			{wave: sampler.Note(music.NoteA4), at: 300 * time.Millisecond, want: 3},       // recorded speed
+			{wave: sampler.Note(music.NoteA5), at: 300 * time.Millisecond, want: 6},       // twice as fast
+			{wave: sampler.Note(music.NoteA3), at: 300 * time.Millisecond, want: 1},       // twice as slow
+			{wave: sampler.Semitones(12), at: 400 * time.Millisecond, want: 8},            // one octave up
+			{wave: sampler.Semitones(-12), at: 1800 * time.Millisecond, want: 9},          // one octave down
+			{wave: sound.NewSynthWave(sampler, 880), at: 200 * time.Millisecond, want: 4}, // as a synthesizer
+		}
+
+		for i, test := range tests {
+			got, err := test.wave.Value(test.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should end one-shot samples", func(t *testing.T) {
+		sampler := NewSampler(sample, music.NoteA4)
+		_, err := sampler.Note(music.NoteA5).Value(600 * time.Millisecond)
+		if !errors.Is(err, sound.ErrEndOfWave) {
+			t.Fatalf("want error %s but got %v", sound.ErrEndOfWave, err)
+		}
+	})
+
+	t.Run("Should return an error for looping samples with an invalid sample rate", func(t *testing.T) {
+		sampler := NewSampler(NewSample(frames, 0), music.NoteA4).Loop(0, 0)
+		if _, err := sampler.Synthesize(440, 0); !errors.Is(err, sound.ErrEndOfWave) {
+			t.Fatalf("want error %s but got %v", sound.ErrEndOfWave, err)
+		}
+	})
+
+	t.Run("Should repeat the loop until the note ends", func(t *testing.T) {
+		tests := []struct {
+			sampler Sampler
+			at      time.Duration
+			want    float64
+		}{
+			{sampler: NewSampler(sample, music.NoteA4).Loop(500*time.Millisecond, 0), at: 400 * time.Millisecond, want: 4},
+			{sampler: NewSampler(sample, music.NoteA4).Loop(500*time.Millisecond, 0), at: 1200 * time.Millisecond, want: 7},
+			{sampler: NewSampler(sample, music.NoteA4).Loop(500*time.Millisecond, 0), at: 10 * time.Second, want: 5},
+			{sampler: NewSampler(sample, music.NoteA4).Loop(200*time.Millisecond, 400*time.Millisecond), at: 500 * time.Millisecond, want: 3},
+			{sampler: NewSampler(sample, music.NoteA4).Loop(0, 0), at: 2300 * time.Millisecond, want: 3},
+		}
+
+		for i, test := range tests {
+			got, err := test.sampler.Synthesize(music.NoteA4.Hz(), test.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-test.want) > 0.0000001 {
+				t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+
+		_, err := NewSampler(sample, music.NoteA4).Loop(0, 0).OneShot().Synthesize(music.NoteA4.Hz(), 2*time.Second)
+		if !errors.Is(err, sound.ErrEndOfWave) {
+			t.Fatalf("want error %s after switching back to one-shot but got %v", sound.ErrEndOfWave, err)
+		}
+	})
+}
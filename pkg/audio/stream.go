@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// StreamBlockSize is the number of frames (per channel) rendered at once by encoders and players that use a stream.
+const StreamBlockSize = 4096
+
+// StreamEncoder encodes the frames of a stream to a writer, block by block.
+type StreamEncoder interface {
+	EncodeStream(w io.Writer, s *Stream) error
+}
+
+// Stream renders a wave block by block,
+// it allows to encode or play long compositions without holding all of their frames in memory.
+//
+// You must call NewStream, NewStereoStream or NewProcessorStream to create a stream.
+type Stream struct {
+	sampleRate  int
+	numChannels int
+	startOffset time.Duration
+	length      int // number of frames per channel
+	index       int // index of the next frame to render
+	render      func(at time.Duration, dst []float64) error
+	processor   sound.Processor // renders whole blocks instead of render when set
+}
+
+// NewStream creates a stream of mono frames generated using the provided sound wave.
+func NewStream(wave sound.Wave, sampleRate int, startOffset, duration time.Duration) (*Stream, error) {
+	if wave == nil {
+		return nil, errors.New("wave is not defined, unable to create stream")
+	}
+	render := func(at time.Duration, dst []float64) error {
+		val, err := wave.Value(at)
+		dst[0] = val
+		return err
+	}
+	return newStream(render, 1, sampleRate, startOffset, duration)
+}
+
+// NewStereoStream creates a stream of interleaved stereo frames generated using the provided stereo wave.
+func NewStereoStream(wave sound.StereoWave, sampleRate int, startOffset, duration time.Duration) (*Stream, error) {
+	if wave == nil {
+		return nil, errors.New("wave is not defined, unable to create stream")
+	}
+	render := func(at time.Duration, dst []float64) error {
+		left, right, err := wave.StereoValue(at)
+		dst[0], dst[1] = left, right
+		return err
+	}
+	return newStream(render, 2, sampleRate, startOffset, duration)
+}
+
+// NewProcessorStream creates a stream of mono frames rendered block by block by the provided processor.
+// The processor is expected to render frames at the given sample rate.
+func NewProcessorStream(p sound.Processor, sampleRate int, duration time.Duration) (*Stream, error) {
+	if p == nil {
+		return nil, errors.New("processor is not defined, unable to create stream")
+	}
+	s, err := newStream(nil, 1, sampleRate, 0, duration)
+	if err != nil {
+		return nil, err
+	}
+	s.processor = p
+	return s, nil
+}
+
+func newStream(render func(time.Duration, []float64) error, numChannels, sampleRate int, startOffset, duration time.Duration) (*Stream, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", sampleRate)
+	}
+	return &Stream{
+		sampleRate:  sampleRate,
+		numChannels: numChannels,
+		startOffset: startOffset,
+		length:      FrameCount(sampleRate, duration),
+		render:      render,
+	}, nil
+}
+
+// SampleRate returns the number of frames per second (per channel).
+func (s *Stream) SampleRate() int {
+	return s.sampleRate
+}
+
+// NumChannels returns the number of interleaved channels.
+func (s *Stream) NumChannels() int {
+	return s.numChannels
+}
+
+// Len returns the total number of frames (per channel) of the stream.
+func (s *Stream) Len() int {
+	return s.length
+}
+
+// Read renders the next frames to buf and returns the number of values written.
+// When there is more than one channel, frames are interleaved and only complete frames are written,
+// so buf must be able to hold at least one frame for each channel (io.ErrShortBuffer is returned otherwise).
+// Read returns io.EOF once all frames have been rendered.
+func (s *Stream) Read(buf []float64) (int, error) {
+	if s.index >= s.length {
+		return 0, io.EOF
+	}
+
+	if s.processor != nil {
+		n := len(buf)
+		if remaining := s.length - s.index; n > remaining {
+			n = remaining
+		}
+		if err := s.processor.Process(buf[:n]); err != nil {
+			return 0, err
+		}
+		s.index += n
+		return n, nil
+	}
+
+	if len(buf) > 0 && len(buf) < s.numChannels {
+		return 0, io.ErrShortBuffer
+	}
+	n := 0
+	for ; n+s.numChannels <= len(buf) && s.index < s.length; n += s.numChannels {
+		err := s.render(sound.FrameTime(s.startOffset, s.index, s.sampleRate), buf[n:n+s.numChannels])
+		if err != nil {
+			return n, err
+		}
+		s.index++
+	}
+	return n, nil
+}
@@ -0,0 +1,213 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should validate required inputs", func(t *testing.T) {
+		if _, err := NewStream(nil, 44100, 0, time.Second); err == nil {
+			t.Fatal("want an error for a nil wave")
+		}
+		if _, err := NewStream(&sound.MockWave{}, 0, 0, time.Second); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+		if _, err := NewStereoStream(nil, 44100, 0, time.Second); err == nil {
+			t.Fatal("want an error for a nil stereo wave")
+		}
+	})
+
+	t.Run("Should render the same frames as Frames, block by block", func(t *testing.T) {
+		wave := sound.NewSynthWave(&sound.Sine{}, 440)
+		want, err := Frames(wave, 1000, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream, err := NewStream(wave, 1000, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stream.Len() != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), stream.Len())
+		}
+
+		got := []float64{}
+		block := make([]float64, 64)
+		for {
+			n, err := stream.Read(block)
+			got = append(got, block[:n]...)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got[i])
+			}
+		}
+	})
+
+	t.Run("Should render the frames of a processor", func(t *testing.T) {
+		wave := sound.NewSynthWave(&sound.Sine{}, 440)
+		want, err := Frames(wave, 1000, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ProcessorFrames(sound.NewWaveProcessor(wave, 1000, 0), 1000, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream, err := NewProcessorStream(sound.NewWaveProcessor(wave, 1000, 0), 1000, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamed := []float64{}
+		block := make([]float64, 300)
+		for {
+			n, err := stream.Read(block)
+			streamed = append(streamed, block[:n]...)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(got) != len(want) || len(streamed) != len(want) {
+			t.Fatalf("want %d frames but got %d and %d", len(want), len(got), len(streamed))
+		}
+		for i := range want {
+			if got[i] != want[i] || streamed[i] != want[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f and %f", i, want[i], got[i], streamed[i])
+			}
+		}
+
+		if _, err := NewProcessorStream(nil, 1000, time.Second); err == nil {
+			t.Fatal("want an error for a nil processor")
+		}
+	})
+
+	t.Run("Should only write complete interleaved frames", func(t *testing.T) {
+		stream, err := NewStereoStream(sound.NewDualWave(&sound.MockWave{}, &sound.SilentWave{}), 10, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block := make([]float64, 5)
+		n, err := stream.Read(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 4 {
+			t.Fatalf("want 4 values (2 stereo frames) but got %d", n)
+		}
+		if block[0] != 1 || block[1] != 0 || block[2] != 1 || block[3] != 0 {
+			t.Fatalf("unexpected frames: %v", block[:n])
+		}
+
+		if n, err := stream.Read(make([]float64, 1)); n != 0 || !errors.Is(err, io.ErrShortBuffer) {
+			t.Fatalf("want %q for a buffer smaller than a frame but got %d values and %v", io.ErrShortBuffer, n, err)
+		}
+	})
+
+	t.Run("Should render the right number of frames", func(t *testing.T) {
+		tests := []struct {
+			sampleRate int
+			duration   time.Duration
+			want       int
+		}{
+			{sampleRate: 2, duration: 10 * time.Second, want: 20},
+			{sampleRate: 44100, duration: time.Second, want: 44100},
+			{sampleRate: 44100, duration: time.Millisecond, want: 45},
+			{sampleRate: 48000, duration: 10 * time.Minute, want: 48000 * 600},
+			{sampleRate: 48000, duration: 0, want: 0},
+		}
+
+		for _, test := range tests {
+			if got := FrameCount(test.sampleRate, test.duration); got != test.want {
+				t.Fatalf("%d Hz for %s: want %d frames but got %d", test.sampleRate, test.duration, test.want, got)
+			}
+		}
+	})
+}
+
+func TestStreamEncoders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the StreamEncoder interface", func(t *testing.T) {
+		var _ StreamEncoder = &PCMEncoder{}
+		var _ StreamEncoder = &WAVEncoder{}
+	})
+
+	t.Run("Should produce the same output as Encode", func(t *testing.T) {
+		wave := sound.NewSynthWave(&sound.SawTooth{}, 220)
+		sampleRate := 8000
+		frames, err := Frames(wave, sampleRate, 0, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name    string
+			encoder Encoder
+		}{
+			{name: "pcm", encoder: &PCMEncoder{}},
+			{name: "pcm dithered", encoder: &PCMEncoder{Format: FormatS16LE, Dither: true}},
+			{name: "wav", encoder: &WAVEncoder{SampleRate: sampleRate, BitDepth: 24}},
+		}
+
+		for _, test := range tests {
+			want := &bytes.Buffer{}
+			if err := test.encoder.Encode(want, frames); err != nil {
+				t.Fatal(err)
+			}
+
+			stream, err := NewStream(wave, sampleRate, 0, 3*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := &bytes.Buffer{}
+			if err := test.encoder.(StreamEncoder).EncodeStream(got, stream); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Fatalf("%s: stream encoded output is different from the encoded frames", test.name)
+			}
+		}
+	})
+
+	t.Run("Should use the number of channels of the stream", func(t *testing.T) {
+		stream, err := NewStereoStream(sound.UpMix(&sound.MockWave{}), 8000, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := (&WAVEncoder{}).EncodeStream(buf, stream); err != nil {
+			t.Fatal(err)
+		}
+
+		_, numChannels, sampleRate, _, data := parseWAV(t, buf.Bytes())
+		if numChannels != 2 || sampleRate != 8000 || len(data) != 2*8000*2 {
+			t.Fatalf("unexpected header: %d channels, %d Hz, %d bytes of data", numChannels, sampleRate, len(data))
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAV format codes used in the "fmt " chunk.
+const (
+	wavFormatPCM   = 1
+	wavFormatFloat = 3
+)
+
+// WAVEncoder encodes frames to a RIFF/WAVE file.
+// When there is more than one channel, frames must be interleaved (ex: left, right, left, right, etc.).
+//
+// Frame values outside of the -1 to 1 range are clipped and NaN values are encoded as silence.
+type WAVEncoder struct {
+	SampleRate  int // number of frames per second (per channel), must be positive
+	NumChannels int // number of interleaved channels, defaults to 1 (mono)
+	BitDepth    int // 16 or 24 for integer frames, 32 for float frames, defaults to 16
+}
+
+func (e *WAVEncoder) Encode(w io.Writer, frames []float64) error {
+	if len(frames) == 0 {
+		return errors.New("no frames were provided")
+	}
+	if w == nil {
+		return errors.New("no io.Writer was provided")
+	}
+
+	numChannels := e.NumChannels
+	if numChannels == 0 {
+		numChannels = 1
+	}
+	if numChannels < 0 {
+		return fmt.Errorf("invalid number of channels: %d", numChannels)
+	}
+	if len(frames)%numChannels != 0 {
+		return fmt.Errorf("number of frames (%d) is not a multiple of the number of channels (%d)", len(frames), numChannels)
+	}
+
+	bw := bufio.NewWriter(w)
+	sampleFormat, err := e.writeHeader(bw, e.SampleRate, numChannels, len(frames)/numChannels)
+	if err != nil {
+		return err
+	}
+	writeFrames(bw, sampleFormat, frames, nil, true)
+	// chunks must have an even size
+	if len(frames)*sampleFormat.Size()%2 != 0 {
+		bw.WriteByte(0)
+	}
+
+	return bw.Flush()
+}
+
+// EncodeStream encodes the frames of a stream, block by block.
+// The sample rate and number of channels of the stream are used instead of the ones of the encoder.
+func (e *WAVEncoder) EncodeStream(w io.Writer, s *Stream) error {
+	if s == nil || s.Len() == 0 {
+		return errors.New("no frames were provided")
+	}
+	if w == nil {
+		return errors.New("no io.Writer was provided")
+	}
+
+	bw := bufio.NewWriter(w)
+	sampleFormat, err := e.writeHeader(bw, s.SampleRate(), s.NumChannels(), s.Len())
+	if err != nil {
+		return err
+	}
+	err = writeStream(bw, sampleFormat, s, nil, true)
+	if err != nil {
+		return err
+	}
+	// chunks must have an even size
+	if s.Len()*s.NumChannels()*sampleFormat.Size()%2 != 0 {
+		bw.WriteByte(0)
+	}
+
+	return bw.Flush()
+}
+
+// writeHeader writes the header of a WAV file, up to the start of the data chunk.
+// It returns the sample format used to encode frames in the data chunk.
+func (e *WAVEncoder) writeHeader(bw *bufio.Writer, sampleRate, numChannels, numFrames int) (SampleFormat, error) {
+	if sampleRate <= 0 {
+		return "", fmt.Errorf("invalid sample rate: %d, sample rate must be positive", sampleRate)
+	}
+
+	bitDepth := e.BitDepth
+	if bitDepth == 0 {
+		bitDepth = 16
+	}
+	format := wavFormatPCM
+	var sampleFormat SampleFormat
+	switch bitDepth {
+	case 16:
+		sampleFormat = FormatS16LE
+	case 24:
+		sampleFormat = FormatS24LE
+	case 32:
+		format = wavFormatFloat
+		sampleFormat = FormatF32LE
+	default:
+		return "", fmt.Errorf("unsupported bit depth: %d, must be 16, 24 or 32", bitDepth)
+	}
+
+	bytesPerFrame := bitDepth / 8
+	dataSize := numFrames * numChannels * bytesPerFrame
+	if dataSize > math.MaxUint32-64 {
+		return "", fmt.Errorf("too many frames to fit in a WAV file: %d", numFrames*numChannels)
+	}
+
+	// float files have an extended "fmt " chunk and a "fact" chunk
+	fmtSize := 16
+	if format == wavFormatFloat {
+		fmtSize = 18
+	}
+	riffSize := 4 + (8 + fmtSize) + (8 + dataSize) + dataSize%2
+	if format == wavFormatFloat {
+		riffSize += 8 + 4
+	}
+
+	// RIFF header
+	bw.WriteString("RIFF")
+	writeUint32(bw, uint32(riffSize))
+	bw.WriteString("WAVE")
+
+	// "fmt " chunk
+	bw.WriteString("fmt ")
+	writeUint32(bw, uint32(fmtSize))
+	writeUint16(bw, uint16(format))
+	writeUint16(bw, uint16(numChannels))
+	writeUint32(bw, uint32(sampleRate))
+	writeUint32(bw, uint32(sampleRate*numChannels*bytesPerFrame)) // byte rate
+	writeUint16(bw, uint16(numChannels*bytesPerFrame))            // block align
+	writeUint16(bw, uint16(bitDepth))
+	if format == wavFormatFloat {
+		writeUint16(bw, 0) // size of the extension
+
+		// "fact" chunk
+		bw.WriteString("fact")
+		writeUint32(bw, 4)
+		writeUint32(bw, uint32(numFrames))
+	}
+
+	// "data" chunk header
+	bw.WriteString("data")
+	writeUint32(bw, uint32(dataSize))
+
+	return sampleFormat, nil
+}
+
+func writeUint16(w *bufio.Writer, v uint16) {
+	var buf [2]byte
+	binary.LittleEndian.PutUint16(buf[:], v)
+	w.Write(buf[:])
+}
+
+func writeUint32(w *bufio.Writer, v uint32) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	w.Write(buf[:])
+}
+
+// WAV format code used by files that describe their actual format in a sub-format GUID.
+const wavFormatExtensible = 0xFFFE
+
+// Size of the largest supported "fmt " chunk (WAVE_FORMAT_EXTENSIBLE, including its 22 bytes extension).
+const wavMaxFormatSize = 40
+
+// Size written in the RIFF and "data" chunk headers by encoders that can't seek back to the header
+// once the length is known (for ex: ffmpeg writing to a pipe), 0 is also used.
+const wavUnknownSize = 0xFFFFFFFF
+
+// ErrInvalidWAV means that the decoded data is not a valid or supported WAV file.
+var ErrInvalidWAV = errors.New("invalid WAV file")
+
+// WAVDecoder decodes a RIFF/WAVE file to a sample.
+// It supports 8, 16, 24 and 32 bit integer frames as well as 32 and 64 bit float frames.
+// Chunks other than "fmt " and "data" are ignored.
+// Streamed files, whose RIFF size is unknown (0 or 0xFFFFFFFF), are read until the end of the "data" chunk,
+// or until EOF when its size is unknown too.
+type WAVDecoder struct{}
+
+func (d *WAVDecoder) Decode(r io.Reader) (*Sample, error) {
+	if r == nil {
+		return nil, errors.New("no io.Reader was provided")
+	}
+
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: failed to read RIFF header: %s", ErrInvalidWAV, err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: missing RIFF/WAVE header", ErrInvalidWAV)
+	}
+	riffSize := binary.LittleEndian.Uint32(header[4:8])
+	streamed := riffSize == 0 || riffSize == wavUnknownSize
+	remaining := int64(riffSize) - 4 // bytes left in the RIFF chunk after "WAVE"
+
+	var (
+		hasFormat   bool
+		format      int
+		numChannels int
+		sampleRate  int
+		bitDepth    int
+	)
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return nil, fmt.Errorf("%w: no data chunk found: %s", ErrInvalidWAV, err)
+		}
+		id := string(chunkHeader[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		untilEOF := id == "data" && (size == wavUnknownSize || (streamed && size == 0))
+		if !streamed && !untilEOF {
+			remaining -= 8
+			if size > remaining {
+				return nil, fmt.Errorf("%w: %q chunk is larger than the RIFF chunk (%d bytes)", ErrInvalidWAV, id, size)
+			}
+			remaining -= size + size%2
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("%w: \"fmt \" chunk is too small (%d bytes)", ErrInvalidWAV, size)
+			}
+			if size > wavMaxFormatSize {
+				return nil, fmt.Errorf("%w: \"fmt \" chunk is too large (%d bytes)", ErrInvalidWAV, size)
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, fmt.Errorf("%w: failed to read \"fmt \" chunk: %s", ErrInvalidWAV, err)
+			}
+			format = int(binary.LittleEndian.Uint16(body[0:2]))
+			numChannels = int(binary.LittleEndian.Uint16(body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitDepth = int(binary.LittleEndian.Uint16(body[14:16]))
+			// the actual format is in the first two bytes of the sub-format GUID
+			if format == wavFormatExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(body[24:26]))
+			}
+			if numChannels <= 0 || sampleRate <= 0 {
+				return nil, fmt.Errorf("%w: %d channels at %d Hz", ErrInvalidWAV, numChannels, sampleRate)
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, fmt.Errorf("%w: \"data\" chunk found before \"fmt \" chunk", ErrInvalidWAV)
+			}
+			decodeFrame, err := wavFrameDecoder(format, bitDepth)
+			if err != nil {
+				return nil, err
+			}
+			dataReader := io.LimitReader(r, size)
+			if untilEOF {
+				dataReader = r
+			}
+			data, err := io.ReadAll(dataReader)
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to read \"data\" chunk: %s", ErrInvalidWAV, err)
+			}
+			if !untilEOF && int64(len(data)) != size {
+				return nil, fmt.Errorf("%w: \"data\" chunk is truncated", ErrInvalidWAV)
+			}
+
+			bytesPerFrame := bitDepth / 8
+			frames := make([]float64, len(data)/(bytesPerFrame*numChannels)*numChannels)
+			for i := range frames {
+				frames[i] = decodeFrame(data[i*bytesPerFrame:])
+			}
+			return NewMultiChannelSample(frames, sampleRate, numChannels), nil
+		default:
+			// skip unknown chunk (and its padding byte)
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("%w: failed to skip %q chunk: %s", ErrInvalidWAV, id, err)
+			}
+			continue
+		}
+
+		// skip padding byte of odd sized chunks
+		if size%2 != 0 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidWAV, err)
+			}
+		}
+	}
+}
+
+// wavFrameDecoder returns a function that converts the bytes of one frame to a value between -1 and 1.
+func wavFrameDecoder(format, bitDepth int) (func(b []byte) float64, error) {
+	var sampleFormat SampleFormat
+	switch {
+	case format == wavFormatPCM && bitDepth == 8:
+		// 8-bit frames are unsigned
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case format == wavFormatPCM && bitDepth == 16:
+		sampleFormat = FormatS16LE
+	case format == wavFormatPCM && bitDepth == 24:
+		sampleFormat = FormatS24LE
+	case format == wavFormatPCM && bitDepth == 32:
+		sampleFormat = FormatS32LE
+	case format == wavFormatFloat && bitDepth == 32:
+		sampleFormat = FormatF32LE
+	case format == wavFormatFloat && bitDepth == 64:
+		sampleFormat = FormatF64LE
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %d with %d bits per frame", ErrInvalidWAV, format, bitDepth)
+	}
+	return sampleFormat.decode, nil
+}
@@ -0,0 +1,336 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+// parseWAV reads the header fields and the raw data of a canonical WAV file.
+func parseWAV(t *testing.T, b []byte) (format, numChannels, sampleRate, bitDepth int, data []byte) {
+	t.Helper()
+
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		t.Fatalf("missing RIFF/WAVE header, got %q", b[:12])
+	}
+	if got := int(binary.LittleEndian.Uint32(b[4:8])); got != len(b)-8 {
+		t.Fatalf("unexpected RIFF chunk size, want %d but got %d", len(b)-8, got)
+	}
+
+	for pos := 12; pos+8 <= len(b); {
+		id := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		body := b[pos+8 : pos+8+size]
+		switch id {
+		case "fmt ":
+			format = int(binary.LittleEndian.Uint16(body[0:2]))
+			numChannels = int(binary.LittleEndian.Uint16(body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitDepth = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			data = body
+		}
+		pos += 8 + size + size%2
+	}
+	return format, numChannels, sampleRate, bitDepth, data
+}
+
+func TestWAVEncoder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Encoder interface", func(t *testing.T) {
+		var _ Encoder = &WAVEncoder{}
+	})
+
+	t.Run("Should validate inputs", func(t *testing.T) {
+		validFrames := []float64{1.0, 0.0}
+		tests := []struct {
+			encoder *WAVEncoder
+			writer  io.Writer
+			frames  []float64
+			wantErr bool
+		}{
+			{encoder: &WAVEncoder{SampleRate: 44100}, writer: &bytes.Buffer{}, frames: validFrames, wantErr: false},
+			{encoder: &WAVEncoder{SampleRate: 44100}, writer: nil, frames: validFrames, wantErr: true},
+			{encoder: &WAVEncoder{SampleRate: 44100}, writer: &bytes.Buffer{}, frames: []float64{}, wantErr: true},
+			{encoder: &WAVEncoder{SampleRate: 0}, writer: &bytes.Buffer{}, frames: validFrames, wantErr: true},
+			{encoder: &WAVEncoder{SampleRate: 44100, BitDepth: 12}, writer: &bytes.Buffer{}, frames: validFrames, wantErr: true},
+			{encoder: &WAVEncoder{SampleRate: 44100, NumChannels: 3}, writer: &bytes.Buffer{}, frames: validFrames, wantErr: true},
+		}
+
+		for i, test := range tests {
+			err := test.encoder.Encode(test.writer, test.frames)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error at index %d, wantErr is %v but got %v", i, test.wantErr, err)
+			}
+		}
+	})
+
+	t.Run("Should write the right header", func(t *testing.T) {
+		tests := []struct {
+			encoder    WAVEncoder
+			wantFormat int
+			wantDepth  int
+		}{
+			{encoder: WAVEncoder{SampleRate: 22050}, wantFormat: wavFormatPCM, wantDepth: 16},
+			{encoder: WAVEncoder{SampleRate: 48000, NumChannels: 2, BitDepth: 24}, wantFormat: wavFormatPCM, wantDepth: 24},
+			{encoder: WAVEncoder{SampleRate: 96000, NumChannels: 2, BitDepth: 32}, wantFormat: wavFormatFloat, wantDepth: 32},
+		}
+
+		for i, test := range tests {
+			buf := &bytes.Buffer{}
+			err := test.encoder.Encode(buf, []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6})
+			if err != nil {
+				t.Fatalf("unexpected error at index %d: %s", i, err)
+			}
+
+			format, numChannels, sampleRate, bitDepth, data := parseWAV(t, buf.Bytes())
+			wantChannels := test.encoder.NumChannels
+			if wantChannels == 0 {
+				wantChannels = 1
+			}
+			if format != test.wantFormat || numChannels != wantChannels ||
+				sampleRate != test.encoder.SampleRate || bitDepth != test.wantDepth {
+				t.Fatalf("unexpected header at index %d: format %d, %d channels, %d Hz, %d bits",
+					i, format, numChannels, sampleRate, bitDepth)
+			}
+			if len(data) != 6*test.wantDepth/8 {
+				t.Fatalf("unexpected data size at index %d: %d bytes", i, len(data))
+			}
+		}
+	})
+
+	t.Run("Should round-trip frames and clip out of range values", func(t *testing.T) {
+		frames := []float64{0, 0.5, -0.5, 1, -1, 1.5, -3, math.NaN()}
+		want := []float64{0, 0.5, -0.5, 1, -1, 1, -1, 0}
+
+		tests := []struct {
+			bitDepth  int
+			precision float64
+			decode    func(b []byte) float64
+		}{
+			{bitDepth: 16, precision: 1.0 / math.MaxInt16, decode: func(b []byte) float64 {
+				return float64(int16(binary.LittleEndian.Uint16(b))) / math.MaxInt16
+			}},
+			{bitDepth: 24, precision: 1.0 / (1<<23 - 1), decode: func(b []byte) float64 {
+				v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+				return float64(v) / (1<<23 - 1)
+			}},
+			{bitDepth: 32, precision: 1e-7, decode: func(b []byte) float64 {
+				return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+			}},
+		}
+
+		for _, test := range tests {
+			buf := &bytes.Buffer{}
+			err := (&WAVEncoder{SampleRate: 44100, BitDepth: test.bitDepth}).Encode(buf, frames)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, _, _, data := parseWAV(t, buf.Bytes())
+			size := test.bitDepth / 8
+			for i, w := range want {
+				got := test.decode(data[i*size : (i+1)*size])
+				if math.Abs(got-w) > test.precision {
+					t.Fatalf("%d bits: unexpected frame value at index %d, want %f but got %f", test.bitDepth, i, w, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Should pad odd sized data chunks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := (&WAVEncoder{SampleRate: 8000, BitDepth: 24}).Encode(buf, []float64{0.25})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len()%2 != 0 {
+			t.Fatalf("want an even file size but got %d bytes", buf.Len())
+		}
+	})
+}
+
+// buildWAV creates a WAV file with the given format, raw data and extra chunks (placed before the data chunk).
+func buildWAV(format, numChannels, sampleRate, bitDepth int, data []byte, extraChunks ...[]byte) []byte {
+	body := &bytes.Buffer{}
+	body.WriteString("WAVE")
+	body.WriteString("fmt ")
+	binary.Write(body, binary.LittleEndian, uint32(16))
+	binary.Write(body, binary.LittleEndian, uint16(format))
+	binary.Write(body, binary.LittleEndian, uint16(numChannels))
+	binary.Write(body, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(body, binary.LittleEndian, uint32(sampleRate*numChannels*bitDepth/8))
+	binary.Write(body, binary.LittleEndian, uint16(numChannels*bitDepth/8))
+	binary.Write(body, binary.LittleEndian, uint16(bitDepth))
+	for _, chunk := range extraChunks {
+		body.Write(chunk)
+	}
+	body.WriteString("data")
+	binary.Write(body, binary.LittleEndian, uint32(len(data)))
+	body.Write(data)
+
+	out := &bytes.Buffer{}
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func TestWAVDecoder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Decoder interface", func(t *testing.T) {
+		var _ Decoder = &WAVDecoder{}
+	})
+
+	t.Run("Should validate inputs", func(t *testing.T) {
+		tests := []struct {
+			reader  io.Reader
+			wantErr bool
+		}{
+			{reader: bytes.NewReader(buildWAV(wavFormatPCM, 1, 8000, 16, []byte{0, 0})), wantErr: false},
+			{reader: nil, wantErr: true},
+			{reader: bytes.NewReader([]byte{}), wantErr: true},
+			{reader: bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI ")), wantErr: true},
+			{reader: bytes.NewReader(buildWAV(wavFormatPCM, 1, 8000, 12, []byte{0, 0})), wantErr: true},
+			{reader: bytes.NewReader(buildWAV(wavFormatPCM, 1, 8000, 16, []byte{0, 0})[:45]), wantErr: true},
+			{reader: bytes.NewReader([]byte("RIFF\xff\xff\xff\xffWAVEfmt \x00\x00\x00\xcd")), wantErr: true}, // huge "fmt " chunk
+			{reader: bytes.NewReader([]byte("RIFF\x10\x00\x00\x00WAVEfmt \x10\x00\x00\x00")), wantErr: true}, // chunk larger than the RIFF chunk
+		}
+
+		for i, test := range tests {
+			_, err := (&WAVDecoder{}).Decode(test.reader)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error at index %d, wantErr is %v but got %v", i, test.wantErr, err)
+			}
+		}
+	})
+
+	t.Run("Should decode frames encoded with WAVEncoder", func(t *testing.T) {
+		frames := []float64{0, 0.5, -0.5, 0.99, -0.99, 0.25}
+		for _, bitDepth := range []int{16, 24, 32} {
+			buf := &bytes.Buffer{}
+			err := (&WAVEncoder{SampleRate: 48000, BitDepth: bitDepth}).Encode(buf, frames)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sample, err := (&WAVDecoder{}).Decode(buf)
+			if err != nil {
+				t.Fatalf("%d bits: unexpected error: %s", bitDepth, err)
+			}
+			if sample.sampleRate != 48000 {
+				t.Fatalf("%d bits: want sample rate 48000 but got %d", bitDepth, sample.sampleRate)
+			}
+			if len(sample.frames) != len(frames) {
+				t.Fatalf("%d bits: want %d frames but got %d", bitDepth, len(frames), len(sample.frames))
+			}
+			for i, want := range frames {
+				if math.Abs(sample.frames[i]-want) > 0.0001 {
+					t.Fatalf("%d bits: unexpected frame value at index %d, want %f but got %f", bitDepth, i, want, sample.frames[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should decode all supported frame formats", func(t *testing.T) {
+		float32Bytes := func(v float32) []byte {
+			b := make([]byte, 4)
+			binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+			return b
+		}
+		float64Bytes := func(v float64) []byte {
+			b := make([]byte, 8)
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+			return b
+		}
+
+		tests := []struct {
+			format   int
+			bitDepth int
+			data     []byte
+			want     float64
+		}{
+			{format: wavFormatPCM, bitDepth: 8, data: []byte{192}, want: 0.5},
+			{format: wavFormatPCM, bitDepth: 16, data: []byte{0x00, 0xC0}, want: -0.5},
+			{format: wavFormatPCM, bitDepth: 24, data: []byte{0x00, 0x00, 0x40}, want: 0.5},
+			{format: wavFormatPCM, bitDepth: 32, data: []byte{0x00, 0x00, 0x00, 0xC0}, want: -0.5},
+			{format: wavFormatFloat, bitDepth: 32, data: float32Bytes(0.25), want: 0.25},
+			{format: wavFormatFloat, bitDepth: 64, data: float64Bytes(-0.75), want: -0.75},
+		}
+
+		for i, test := range tests {
+			sample, err := (&WAVDecoder{}).Decode(bytes.NewReader(buildWAV(test.format, 1, 8000, test.bitDepth, test.data)))
+			if err != nil {
+				t.Fatalf("unexpected error at index %d: %s", i, err)
+			}
+			if len(sample.frames) != 1 || math.Abs(sample.frames[0]-test.want) > 0.0001 {
+				t.Fatalf("unexpected frames at index %d, want [%f] but got %v", i, test.want, sample.frames)
+			}
+		}
+	})
+
+	t.Run("Should decode stereo files and ignore unknown chunks", func(t *testing.T) {
+		listChunk := append([]byte("LIST\x03\x00\x00\x00abc"), 0)      // odd sized chunk with padding
+		data := []byte{0x00, 0x40, 0x00, 0x00, 0x00, 0xC0, 0x00, 0xC0} // (0.5, 0), (-0.5, -0.5)
+		sample, err := (&WAVDecoder{}).Decode(bytes.NewReader(buildWAV(wavFormatPCM, 2, 44100, 16, data, listChunk)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sample.NumChannels() != 2 {
+			t.Fatalf("want 2 channels but got %d", sample.NumChannels())
+		}
+		want := []float64{0.5, 0, -0.5, -0.5}
+		if len(sample.frames) != len(want) {
+			t.Fatalf("want %d frames but got %d", len(want), len(sample.frames))
+		}
+		for i := range want {
+			if math.Abs(sample.frames[i]-want[i]) > 0.0001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], sample.frames[i])
+			}
+		}
+	})
+
+	t.Run("Should decode streamed files with unknown sizes", func(t *testing.T) {
+		data := []byte{0x00, 0x40, 0x00, 0xC0, 0x00} // 0.5, -0.5 and an incomplete frame
+		for _, size := range []uint32{0, wavUnknownSize} {
+			b := buildWAV(wavFormatPCM, 1, 8000, 16, data)
+			binary.LittleEndian.PutUint32(b[4:8], size)   // RIFF size
+			binary.LittleEndian.PutUint32(b[40:44], size) // "data" size
+			sample, err := (&WAVDecoder{}).Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("size %#x: unexpected error: %s", size, err)
+			}
+			want := []float64{0.5, -0.5}
+			if len(sample.frames) != len(want) || sample.frames[0] != want[0] || sample.frames[1] != want[1] {
+				t.Fatalf("size %#x: want frames %v but got %v", size, want, sample.frames)
+			}
+		}
+	})
+
+	t.Run("Should round-trip stereo files", func(t *testing.T) {
+		frames := []float64{0.5, -0.5, 0.25, -0.25}
+		buf := &bytes.Buffer{}
+		err := (&WAVEncoder{SampleRate: 44100, NumChannels: 2, BitDepth: 32}).Encode(buf, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sample, err := (&WAVDecoder{}).Decode(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for c, want := range [][]float64{{0.5, 0.25}, {-0.5, -0.25}} {
+			got := sample.Channel(c)
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("channel %d: want %v but got %v", c, want, got)
+				}
+			}
+		}
+	})
+}
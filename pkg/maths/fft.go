@@ -0,0 +1,63 @@
+package maths
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// FFT computes the discrete Fourier transform of x in place, using the radix-2 Cooley-Tukey algorithm.
+// The length of x must be a power of two.
+func FFT(x []complex128) error {
+	return fft(x, false)
+}
+
+// InverseFFT computes the inverse discrete Fourier transform of x in place (including the 1/n scaling),
+// so that InverseFFT(FFT(x)) returns x. The length of x must be a power of two.
+func InverseFFT(x []complex128) error {
+	if err := fft(x, true); err != nil {
+		return err
+	}
+	scale := complex(1/float64(len(x)), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+	return nil
+}
+
+func fft(x []complex128, inverse bool) error {
+	n := len(x)
+	if n == 0 || n&(n-1) != 0 {
+		return fmt.Errorf("invalid FFT length: %d, length must be a power of two", n)
+	}
+
+	// reorder the values in bit-reversed order
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+	return nil
+}
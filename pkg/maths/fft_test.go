@@ -0,0 +1,68 @@
+package maths
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestFFT(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should compute the discrete Fourier transform", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		for _, n := range []int{1, 2, 8, 64} {
+			x := make([]complex128, n)
+			for i := range x {
+				x[i] = complex(r.Float64()*2-1, r.Float64()*2-1)
+			}
+
+			// naive DFT
+			want := make([]complex128, n)
+			for k := range want {
+				for i, v := range x {
+					want[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*i)/float64(n))
+				}
+			}
+
+			got := append([]complex128(nil), x...)
+			if err := FFT(got); err != nil {
+				t.Fatal(err)
+			}
+			for k := range want {
+				if cmplx.Abs(got[k]-want[k]) > 0.000001 {
+					t.Fatalf("n=%d: unexpected value at index %d, want %v but got %v", n, k, want[k], got[k])
+				}
+			}
+
+			if err := InverseFFT(got); err != nil {
+				t.Fatal(err)
+			}
+			for i := range x {
+				if cmplx.Abs(got[i]-x[i]) > 0.000001 {
+					t.Fatalf("n=%d: unexpected value after the inverse FFT at index %d, want %v but got %v", n, i, x[i], got[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should only accept powers of two", func(t *testing.T) {
+		tests := []struct {
+			n       int
+			wantErr bool
+		}{
+			{n: 0, wantErr: true},
+			{n: 3, wantErr: true},
+			{n: 12, wantErr: true},
+			{n: 16, wantErr: false},
+		}
+
+		for i, test := range tests {
+			err := FFT(make([]complex128, test.n))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error at index %d, wantErr is %v but got %v", i, test.wantErr, err)
+			}
+		}
+	})
+}
@@ -16,3 +16,30 @@ func (i LinearInterpolation) At(x, x1, x2, y1, y2 float64) float64 {
 	progressX := float64(x-x1) / float64(deltaX)
 	return progressX*deltaY + y1
 }
+
+// CubicHermiteInterpolation returns the value at progress t (from 0 to 1) between y1 and y2
+// on a Catmull-Rom spline going through the four consecutive points y0, y1, y2 and y3.
+func CubicHermiteInterpolation(y0, y1, y2, y3, t float64) float64 {
+	c0 := y1
+	c1 := 0.5 * (y2 - y0)
+	c2 := y0 - 2.5*y1 + 2*y2 - 0.5*y3
+	c3 := 0.5*(y3-y0) + 1.5*(y1-y2)
+	return ((c3*t+c2)*t+c1)*t + c0
+}
+
+// Sinc returns the normalized sinc function: sin(πx) / (πx).
+func Sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// BlackmanWindow returns the value of a Blackman window of width 2 centered on 0,
+// it is 1 for x = 0 and 0 when |x| >= 1.
+func BlackmanWindow(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
@@ -40,3 +40,64 @@ func TestInterpolationFunctions(t *testing.T) {
 		}
 	}
 }
+
+func TestCubicHermiteInterpolation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		y0, y1, y2, y3, t, want float64
+	}{
+		{y0: 0, y1: 1, y2: 2, y3: 3, t: 0, want: 1},     // start
+		{y0: 0, y1: 1, y2: 2, y3: 3, t: 1, want: 2},     // end
+		{y0: 0, y1: 1, y2: 2, y3: 3, t: 0.5, want: 1.5}, // straight line
+		{y0: 0, y1: 1, y2: 1, y3: 0, t: 0.5, want: 1.125},
+	}
+
+	for i, test := range tests {
+		got := CubicHermiteInterpolation(test.y0, test.y1, test.y2, test.y3, test.t)
+		if math.Abs(got-test.want) > 0.0000001 {
+			t.Fatalf("test %d: want %v, got %v", i, test.want, got)
+		}
+	}
+}
+
+func TestSinc(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		x, want float64
+	}{
+		{x: 0, want: 1},
+		{x: 1, want: 0},
+		{x: -2, want: 0},
+		{x: 0.5, want: 2 / math.Pi},
+	}
+
+	for i, test := range tests {
+		got := Sinc(test.x)
+		if math.Abs(got-test.want) > 0.0000001 {
+			t.Fatalf("test %d: want %v, got %v", i, test.want, got)
+		}
+	}
+}
+
+func TestBlackmanWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		x, want float64
+	}{
+		{x: 0, want: 1},
+		{x: 1, want: 0},
+		{x: -1, want: 0},
+		{x: 3, want: 0},
+		{x: 0.5, want: 0.34},
+	}
+
+	for i, test := range tests {
+		got := BlackmanWindow(test.x)
+		if math.Abs(got-test.want) > 0.0000001 {
+			t.Fatalf("test %d: want %v, got %v", i, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package music
+
+import "strings"
+
+// Rhythm represents which steps of a cycle are played (true) or not (false).
+type Rhythm []bool
+
+// Euclid returns the Euclidean rhythm that spreads the hits as evenly as possible over the steps
+// (using Bjorklund's algorithm) and rotates it by the given number of steps (see Rhythm.Rotate).
+// For example, Euclid(3, 8, 0) is "x..x..x.".
+//
+// The number of hits is clamped between 0 and the number of steps.
+func Euclid(hits, steps, rotation int) Rhythm {
+	if steps <= 0 {
+		return Rhythm{}
+	}
+	if hits < 0 {
+		hits = 0
+	} else if hits > steps {
+		hits = steps
+	}
+
+	// start with groups made of a single hit or rest,
+	// then repeatedly append the remainder groups to the first groups until there is at most one remainder group left.
+	groups := make([]Rhythm, hits)
+	for i := range groups {
+		groups[i] = Rhythm{true}
+	}
+	remainders := make([]Rhythm, steps-hits)
+	for i := range remainders {
+		remainders[i] = Rhythm{false}
+	}
+	for len(remainders) > 1 && len(groups) > 0 {
+		n := len(groups)
+		if len(remainders) < n {
+			n = len(remainders)
+		}
+		merged := make([]Rhythm, n)
+		for i := 0; i < n; i++ {
+			merged[i] = append(groups[i], remainders[i]...)
+		}
+		if len(groups) > n {
+			remainders = groups[n:]
+		} else {
+			remainders = remainders[n:]
+		}
+		groups = merged
+	}
+
+	out := Rhythm{}
+	for _, group := range append(groups, remainders...) {
+		out = append(out, group...)
+	}
+	return out.Rotate(rotation)
+}
+
+// ParseRhythm creates a rhythm from a string where 'x' or 'X' are hits and any other character is a rest (ex: "x..x..x.").
+func ParseRhythm(s string) Rhythm {
+	out := make(Rhythm, len(s))
+	for i, c := range []byte(s) {
+		out[i] = c == 'x' || c == 'X'
+	}
+	return out
+}
+
+// String returns the rhythm with 'x' for hits and '.' for rests.
+func (r Rhythm) String() string {
+	b := strings.Builder{}
+	for _, hit := range r {
+		if hit {
+			b.WriteByte('x')
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// Hits returns the number of hits of the rhythm.
+func (r Rhythm) Hits() int {
+	count := 0
+	for _, hit := range r {
+		if hit {
+			count++
+		}
+	}
+	return count
+}
+
+// Rotate returns the rhythm shifted by the given number of steps, steps that go past the end wrap around.
+// Positive values shift the hits later (ex: "x..." rotated by 1 is ".x.."), negative values shift them earlier.
+func (r Rhythm) Rotate(steps int) Rhythm {
+	out := make(Rhythm, len(r))
+	if len(r) == 0 {
+		return out
+	}
+	steps = ((steps % len(r)) + len(r)) % len(r)
+	for i, hit := range r {
+		out[(i+steps)%len(r)] = hit
+	}
+	return out
+}
+
+// Invert returns the rhythm with hits and rests swapped.
+func (r Rhythm) Invert() Rhythm {
+	out := make(Rhythm, len(r))
+	for i, hit := range r {
+		out[i] = !hit
+	}
+	return out
+}
+
+// Union returns the rhythm made of the hits of both rhythms.
+// If the rhythms have different lengths, they are repeated until they both end at the same time,
+// so the output length is the least common multiple of both lengths (ex: 12 steps for 3 and 4 steps).
+func (r Rhythm) Union(other Rhythm) Rhythm {
+	return combine(r, other, func(a, b bool) bool { return a || b })
+}
+
+// Intersect returns the rhythm made of the hits that are in both rhythms.
+// If the rhythms have different lengths, they are repeated like with Union.
+func (r Rhythm) Intersect(other Rhythm) Rhythm {
+	return combine(r, other, func(a, b bool) bool { return a && b })
+}
+
+func combine(a, b Rhythm, fn func(a, b bool) bool) Rhythm {
+	if len(a) == 0 || len(b) == 0 {
+		return Rhythm{}
+	}
+	out := make(Rhythm, lcm(len(a), len(b)))
+	for i := range out {
+		out[i] = fn(a[i%len(a)], b[i%len(b)])
+	}
+	return out
+}
+
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
@@ -0,0 +1,56 @@
+package music
+
+import "testing"
+
+func TestEuclid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		hits, steps, rotation int
+		want                  string
+	}{
+		{hits: 3, steps: 8, want: "x..x..x."},
+		{hits: 5, steps: 8, want: "x.xx.xx."},
+		{hits: 2, steps: 5, want: "x.x.."},
+		{hits: 4, steps: 12, want: "x..x..x..x.."},
+		{hits: 5, steps: 16, want: "x..x..x..x..x..."},
+		{hits: 7, steps: 16, want: "x..x.x.x..x.x.x."},
+		{hits: 3, steps: 8, rotation: 1, want: ".x..x..x"},
+		{hits: 3, steps: 8, rotation: -1, want: "..x..x.x"},
+		{hits: 0, steps: 4, want: "...."},
+		{hits: 4, steps: 4, want: "xxxx"},
+		{hits: 6, steps: 4, want: "xxxx"},
+		{hits: 1, steps: 0, want: ""},
+	}
+
+	for _, test := range tests {
+		got := Euclid(test.hits, test.steps, test.rotation)
+		if got.String() != test.want {
+			t.Fatalf("E(%d, %d) rotated by %d: want %q but got %q", test.hits, test.steps, test.rotation, test.want, got)
+		}
+	}
+}
+
+func TestRhythmCombinations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		got  Rhythm
+		want string
+	}{
+		{got: ParseRhythm("x..x").Union(ParseRhythm(".x.x")), want: "xx.x"},
+		{got: ParseRhythm("x..x").Intersect(ParseRhythm(".x.x")), want: "...x"},
+		{got: ParseRhythm("x..").Union(ParseRhythm("x.")), want: "x.xxx."},
+		{got: ParseRhythm("x..").Intersect(ParseRhythm("x...")), want: "x..........."},
+		{got: ParseRhythm("x.x.").Invert(), want: ".x.x"},
+		{got: ParseRhythm("x..").Rotate(4), want: ".x."},
+		{got: ParseRhythm("").Rotate(1), want: ""},
+		{got: ParseRhythm("x.").Union(Rhythm{}), want: ""},
+	}
+
+	for i, test := range tests {
+		if test.got.String() != test.want {
+			t.Fatalf("test %d: want %q but got %q", i, test.want, test.got)
+		}
+	}
+}
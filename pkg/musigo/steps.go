@@ -0,0 +1,139 @@
+package musigo
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+// Step velocities used by ParseSteps.
+const (
+	VelocityAccent = 1.0
+	VelocityNormal = 0.7
+	VelocityGhost  = 0.35
+)
+
+// Step is one step of a step sequencer.
+type Step struct {
+	Velocity    float64 // gain applied to the wave, 0 for a rest
+	Probability float64 // chance for the step to be played, from 0 to 1
+}
+
+// Steps is a sequence of steps, it represents a drum machine pattern.
+type Steps []Step
+
+// ErrInvalidSteps means that a step sequencer string could not be parsed.
+var ErrInvalidSteps = errors.New("invalid steps")
+
+// ParseSteps parses a step sequencer string where each character is a step, for ex: "x...x...x..xx...".
+//
+// The following characters are supported:
+//   - 'X': accented hit
+//   - 'x': hit
+//   - 'g': ghost note (quiet hit)
+//   - '.' or '-': rest
+//   - '|' and spaces are ignored, they can be used to make the string more readable (ex: "x... x... | x..x x...")
+//
+// A hit can be followed by a probability in percent, for ex: "x?50" is a hit that plays half of the time.
+func ParseSteps(s string) (Steps, error) {
+	steps := Steps{}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ', '|':
+		case '.', '-':
+			steps = append(steps, Step{})
+		case 'X', 'x', 'g':
+			velocity := VelocityNormal
+			if c == 'X' {
+				velocity = VelocityAccent
+			} else if c == 'g' {
+				velocity = VelocityGhost
+			}
+			step := Step{Velocity: velocity, Probability: 1}
+
+			if i+1 < len(s) && s[i+1] == '?' {
+				end := i + 2
+				for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+					end++
+				}
+				percent, err := strconv.Atoi(s[i+2 : end])
+				if err != nil || percent > 100 {
+					return nil, fmt.Errorf("%w: invalid probability %q at position %d", ErrInvalidSteps, s[i+1:end], i+1)
+				}
+				step.Probability = float64(percent) / 100
+				i = end - 1
+			}
+			steps = append(steps, step)
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrInvalidSteps, c, i)
+		}
+	}
+	return steps, nil
+}
+
+// MustParseSteps is like ParseSteps but panics if the string is invalid.
+func MustParseSteps(s string) Steps {
+	steps, err := ParseSteps(s)
+	if err != nil {
+		panic(err)
+	}
+	return steps
+}
+
+// RhythmSteps converts a rhythm to steps where each hit has the given velocity.
+func RhythmSteps(rhythm music.Rhythm, velocity float64) Steps {
+	steps := make(Steps, len(rhythm))
+	for i, hit := range rhythm {
+		if hit {
+			steps[i] = Step{Velocity: velocity, Probability: 1}
+		}
+	}
+	return steps
+}
+
+// StepDuration returns the duration of a step when each beat is divided in stepsPerBeat steps
+// (for ex: 4 for sixteenth notes).
+func StepDuration(bpm music.BPM, stepsPerBeat int) time.Duration {
+	return bpm.Time(1 / float64(stepsPerBeat))
+}
+
+// Segments converts the steps to pattern segments where the wave is played, with the step's velocity, at each hit.
+// Each hit lasts until the next one so the wave can ring during the following rests.
+//
+// Steps with a probability below 1 are played or skipped using the provided random number generator,
+// if it is nil, a generator with a fixed seed is used.
+func (steps Steps) Segments(wave sound.Wave, stepDuration time.Duration, r *rand.Rand) []sound.PatternSegment {
+	if r == nil {
+		r = rand.New(rand.NewSource(0))
+	}
+
+	segments := []sound.PatternSegment{}
+	for _, step := range steps {
+		hit := step.Velocity > 0 && (step.Probability >= 1 || r.Float64() < step.Probability)
+		if hit || len(segments) == 0 {
+			segment := sound.PatternSegment{Duration: stepDuration, Wave: sound.SilentWave{}}
+			if hit {
+				segment.Wave = sound.NewAmplifiedWave(wave, step.Velocity)
+			}
+			segments = append(segments, segment)
+			continue
+		}
+		// extend the previous segment
+		segments[len(segments)-1].Duration += stepDuration
+	}
+	return segments
+}
+
+// PlaySteps plays the wave for each hit of the steps, each step lasting stepDuration.
+// The random choices for steps with a probability are made with the track's seed (see Track.Seed).
+func (c *Controller) PlaySteps(stepDuration time.Duration, steps Steps, wave sound.Wave) {
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewSource(c.t.seed))
+	}
+	c.segments = append(c.segments, steps.Segments(wave, stepDuration, c.rand)...)
+}
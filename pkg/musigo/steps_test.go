@@ -0,0 +1,154 @@
+package musigo
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+	"github.com/ejuju/musigo/pkg/sound"
+)
+
+func TestParseSteps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should parse hits, rests and probabilities", func(t *testing.T) {
+		got, err := ParseSteps("Xx.g | -x?50 x?0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Steps{
+			{Velocity: VelocityAccent, Probability: 1},
+			{Velocity: VelocityNormal, Probability: 1},
+			{},
+			{Velocity: VelocityGhost, Probability: 1},
+			{},
+			{Velocity: VelocityNormal, Probability: 0.5},
+			{Velocity: VelocityNormal, Probability: 0},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("want %d steps but got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("unexpected step at index %d, want %+v but got %+v", i, want[i], got[i])
+			}
+		}
+	})
+
+	t.Run("Should reject invalid strings", func(t *testing.T) {
+		tests := []struct {
+			s       string
+			wantErr bool
+		}{
+			{s: "x...x...x..xx...", wantErr: false},
+			{s: "", wantErr: false},
+			{s: "x..o", wantErr: true},  // unknown character
+			{s: "x?", wantErr: true},    // missing probability
+			{s: "x?101", wantErr: true}, // probability above 100%
+			{s: ".?50", wantErr: true},  // probability on a rest
+		}
+
+		for i, test := range tests {
+			_, err := ParseSteps(test.s)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error at index %d, wantErr is %v but got %v", i, test.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSteps) {
+				t.Fatalf("want error %s but got %v", ErrInvalidSteps, err)
+			}
+		}
+	})
+}
+
+func TestRhythmSteps(t *testing.T) {
+	t.Parallel()
+
+	got := RhythmSteps(music.Euclid(3, 8, 0), VelocityAccent)
+	want := MustParseSteps("X..X..X.")
+	if len(got) != len(want) {
+		t.Fatalf("want %d steps but got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected step at index %d, want %+v but got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestStepDuration(t *testing.T) {
+	t.Parallel()
+
+	if got := StepDuration(music.BPM(120), 4); got != 125*time.Millisecond {
+		t.Fatalf("want 125ms but got %s", got)
+	}
+}
+
+func TestStepsSegments(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should play the wave at each hit until the next one", func(t *testing.T) {
+		segments := MustParseSteps("..X.x..g").Segments(sound.MockWave{}, time.Second, nil)
+
+		want := []struct {
+			duration time.Duration
+			value    float64
+		}{
+			{duration: 2 * time.Second, value: 0},
+			{duration: 2 * time.Second, value: VelocityAccent},
+			{duration: 3 * time.Second, value: VelocityNormal},
+			{duration: time.Second, value: VelocityGhost},
+		}
+		if len(segments) != len(want) {
+			t.Fatalf("want %d segments but got %d", len(want), len(segments))
+		}
+		for i, segment := range segments {
+			val, _ := segment.Wave.Value(0)
+			if segment.Duration != want[i].duration || val != want[i].value {
+				t.Fatalf("unexpected segment at index %d, want %s with value %f but got %s with value %f",
+					i, want[i].duration, want[i].value, segment.Duration, val)
+			}
+		}
+	})
+
+	t.Run("Should play steps according to their probability", func(t *testing.T) {
+		steps := Steps{}
+		for i := 0; i < 1000; i++ {
+			steps = append(steps, Step{Velocity: 1, Probability: 0.25})
+		}
+		segments := steps.Segments(sound.MockWave{}, time.Second, rand.New(rand.NewSource(1)))
+
+		hits := 0
+		for _, segment := range segments {
+			if val, _ := segment.Wave.Value(0); val != 0 {
+				hits++
+			}
+		}
+		if hits < 200 || hits > 300 {
+			t.Fatalf("want around 250 hits but got %d", hits)
+		}
+	})
+
+	t.Run("Should be reproducible with the track's seed", func(t *testing.T) {
+		render := func() []float64 {
+			track := NewTrack(nil, func(c *Controller) {
+				c.PlaySteps(time.Second, MustParseSteps("x?50x?50x?50x?50x?50x?50x?50x?50"), sound.MockWave{})
+			}).Seed(42)
+			wave := track.wave()
+			out := []float64{}
+			for i := 0; i < 8; i++ {
+				val, _ := wave.Value(time.Duration(i)*time.Second + time.Millisecond)
+				out = append(out, val)
+			}
+			return out
+		}
+
+		first, second := render(), render()
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, first[i], second[i])
+			}
+		}
+	})
+}
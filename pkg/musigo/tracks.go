@@ -1,6 +1,7 @@
 package musigo
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/ejuju/musigo/pkg/sound"
@@ -12,6 +13,10 @@ type Track struct {
 	synth     sound.Synthesizer
 	trackFunc TrackFunc
 	effects   []sound.Effect
+	pan       float64
+	seed      int64
+
+	stereoEffects []sound.StereoEffect
 }
 
 // TrackFunc is a callback function that gets called when the track gets played.
@@ -21,6 +26,7 @@ type TrackFunc func(*Controller)
 type Controller struct {
 	t        Track
 	segments []sound.PatternSegment
+	rand     *rand.Rand // used for random choices, created from the track's seed when needed
 }
 
 // NewTrack creates a new track.
@@ -28,22 +34,61 @@ func NewTrack(synth sound.Synthesizer, trackFunc TrackFunc, baseEffects ...sound
 	return Track{synth: synth, trackFunc: trackFunc, effects: baseEffects}
 }
 
+// Pan returns a copy of the track placed in the stereo field when the tracks are merged with MergeStereo.
+// The pan goes from -1 (left only) to 1 (right only), 0 (the default) plays the track in both channels.
+func (t Track) Pan(pan float64) Track {
+	t.pan = pan
+	return t
+}
+
+// StereoEffects returns a copy of the track whose output goes through the given stereo effects (for ex: a ping-pong delay)
+// when the tracks are merged with MergeStereo. The effects are applied after the track is panned.
+func (t Track) StereoEffects(effects ...sound.StereoEffect) Track {
+	t.stereoEffects = effects
+	return t
+}
+
+// Seed returns a copy of the track that uses the given seed for its random choices
+// (for ex: steps with a probability), so that the track sounds the same each time it is rendered.
+func (t Track) Seed(seed int64) Track {
+	t.seed = seed
+	return t
+}
+
+// wave returns the output wave of the track.
+func (t Track) wave() sound.Wave {
+	controller := &Controller{segments: []sound.PatternSegment{}, t: t}
+	t.trackFunc(controller)
+	// wrap effects around wave
+	var wave sound.Wave = sound.NewPattern(controller.segments)
+	for _, effect := range t.effects {
+		wave = effect.Wrap(wave)
+	}
+	return wave
+}
+
 // Tracks is a map of track IDs and their corresponding track.
 type Tracks map[string]Track
 
 func (tracks Tracks) Merge() sound.MergedWaves {
 	waves := []sound.Wave{}
 	for _, track := range tracks {
-		controller := &Controller{segments: []sound.PatternSegment{}, t: track}
-		track.trackFunc(controller)
-		// wrap effects around wave
-		var wave sound.Wave = sound.NewPattern(controller.segments)
-		for _, effect := range track.effects {
-			wave = effect.Wrap(wave)
+		waves = append(waves, track.wave())
+	}
+	return sound.NewMergedWaves(waves...)
+}
+
+// MergeStereo is like Merge but places each track in the stereo field according to its pan.
+func (tracks Tracks) MergeStereo() sound.MergedStereoWaves {
+	waves := []sound.StereoWave{}
+	for _, track := range tracks {
+		var wave sound.StereoWave = sound.NewPannedWave(track.wave(), track.pan)
+		for _, effect := range track.stereoEffects {
+			wave = effect.WrapStereo(wave)
 		}
 		waves = append(waves, wave)
 	}
-	return sound.NewMergedWaves(waves...)
+	return sound.NewMergedStereoWaves(waves...)
 }
 
 // Play a sound made of one or more frequencies
@@ -1,6 +1,11 @@
 package musigo
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/sound"
+)
 
 func TestTrack(t *testing.T) {
 	t.Parallel()
@@ -22,4 +27,44 @@ func TestMix(t *testing.T) {
 	t.Parallel()
 
 	t.Run("Mix should generate an output wave from an arbitrary number of tracks", func(t *testing.T) {})
+
+	t.Run("Mix should place panned tracks in the stereo field", func(t *testing.T) {
+		tracks := Tracks{
+			"left": NewTrack(sound.Square{}, func(c *Controller) {
+				c.Play(time.Second, nil, 1)
+			}).Pan(-1),
+			"center": NewTrack(sound.Square{}, func(c *Controller) {
+				c.Play(time.Second, nil, 1)
+			}),
+		}
+
+		left, right, err := tracks.MergeStereo().StereoValue(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// both tracks produce -1 at the start of a square wave cycle
+		if left != -1 || right != -0.5 {
+			t.Fatalf("want (-1, -0.5) but got (%f, %f)", left, right)
+		}
+	})
+
+	t.Run("Mix should apply stereo effects to tracks", func(t *testing.T) {
+		tracks := Tracks{
+			"echo": NewTrack(sound.Square{}, func(c *Controller) {
+				c.Play(time.Second, nil, 1)
+			}).StereoEffects(sound.NewDelay(10, 100*time.Millisecond, 0, 1).PingPong()),
+		}
+
+		wave := tracks.MergeStereo()
+		for i := 0; i < 2; i++ {
+			left, right, err := wave.StereoValue(time.Duration(i) * 100 * time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// nothing until the first echo, which only plays on the left channel
+			if want := float64(-i); left != want || right != 0 {
+				t.Fatalf("unexpected frame at index %d, want (%f, 0) but got (%f, %f)", i, want, left, right)
+			}
+		}
+	})
 }
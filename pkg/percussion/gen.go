@@ -0,0 +1,191 @@
+//go:build ignore
+
+// This program generates the WAV files of the percussion kit.
+// All sounds are synthesized from scratch so the kit can be distributed freely.
+//
+// Run it with go generate from the percussion package.
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/ejuju/musigo/pkg/audio"
+)
+
+const sampleRate = 44100
+
+func main() {
+	voices := map[string]func(r *rand.Rand) []float64{
+		"kick":       kick,
+		"snare":      snare,
+		"closed-hat": func(r *rand.Rand) []float64 { return hat(0.1, 0.02) },
+		"open-hat":   func(r *rand.Rand) []float64 { return hat(0.4, 0.12) },
+		"clap":       clap,
+		"rim":        rim,
+		"low-tom":    func(r *rand.Rand) []float64 { return tom(r, 90) },
+		"mid-tom":    func(r *rand.Rand) []float64 { return tom(r, 130) },
+		"high-tom":   func(r *rand.Rand) []float64 { return tom(r, 180) },
+	}
+
+	for name, voice := range voices {
+		frames := normalize(voice(rand.New(rand.NewSource(1))))
+		if err := write(filepath.Join("samples", name+".wav"), frames); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func write(path string, frames []float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := (&audio.WAVEncoder{SampleRate: sampleRate, BitDepth: 16}).Encode(f, frames); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// render calls fn with the time (in seconds) of each frame of a sound of the given length.
+func render(seconds float64, fn func(t float64) float64) []float64 {
+	frames := make([]float64, int(seconds*sampleRate))
+	for i := range frames {
+		frames[i] = fn(float64(i) / sampleRate)
+	}
+	return frames
+}
+
+// decay returns an exponential decay with the given time constant.
+func decay(t, tau float64) float64 {
+	return math.Exp(-t / tau)
+}
+
+// highPass applies a one-pole high-pass filter to the frames.
+func highPass(frames []float64, cutoff float64) []float64 {
+	a := math.Exp(-2 * math.Pi * cutoff / sampleRate)
+	out := make([]float64, len(frames))
+	prevIn, prevOut := 0.0, 0.0
+	for i, v := range frames {
+		prevOut = a * (prevOut + v - prevIn)
+		prevIn = v
+		out[i] = prevOut
+	}
+	return out
+}
+
+// bandPass applies a two-pole resonant band-pass filter to the frames.
+func bandPass(frames []float64, center, q float64) []float64 {
+	w := 2 * math.Pi * center / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	a0 := 1 + alpha
+	b0, b2 := alpha/a0, -alpha/a0
+	a1, a2 := -2*math.Cos(w)/a0, (1-alpha)/a0
+	out := make([]float64, len(frames))
+	x1, x2, y1, y2 := 0.0, 0.0, 0.0, 0.0
+	for i, x := range frames {
+		y := b0*x + b2*x2 - a1*y1 - a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		out[i] = y
+	}
+	return out
+}
+
+func noise(r *rand.Rand, seconds float64) []float64 {
+	return render(seconds, func(float64) float64 { return 2*r.Float64() - 1 })
+}
+
+// normalize scales the frames to a peak of 0.9 and fades out the last milliseconds.
+func normalize(frames []float64) []float64 {
+	peak := 0.0
+	for _, v := range frames {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	fade := sampleRate / 200
+	for i := range frames {
+		frames[i] *= 0.9 / peak
+		if remaining := len(frames) - i; remaining < fade {
+			frames[i] *= float64(remaining) / float64(fade)
+		}
+	}
+	return frames
+}
+
+func kick(r *rand.Rand) []float64 {
+	phase := 0.0
+	return render(0.5, func(t float64) float64 {
+		freq := 45 + 105*decay(t, 0.03)
+		phase += 2 * math.Pi * freq / sampleRate
+		click := 0.3 * decay(t, 0.002) * (2*r.Float64() - 1)
+		return math.Sin(phase)*decay(t, 0.15) + click
+	})
+}
+
+func snare(r *rand.Rand) []float64 {
+	rattle := highPass(noise(r, 0.35), 1500)
+	i := 0
+	return render(0.35, func(t float64) float64 {
+		body := 0.6*math.Sin(2*math.Pi*185*t) + 0.4*math.Sin(2*math.Pi*330*t)
+		v := body*decay(t, 0.05) + rattle[i]*decay(t, 0.08)
+		i++
+		return v
+	})
+}
+
+// hat mixes square waves at inharmonic frequencies (like analog drum machines) to get a metallic sound.
+func hat(seconds, tau float64) []float64 {
+	freqs := []float64{205.3, 304.4, 369.6, 522.7, 540, 800}
+	metal := render(seconds, func(t float64) float64 {
+		sum := 0.0
+		for _, freq := range freqs {
+			if math.Mod(t*freq*2, 1) < 0.5 {
+				sum++
+			} else {
+				sum--
+			}
+		}
+		return sum / float64(len(freqs))
+	})
+	metal = highPass(bandPass(metal, 10000, 0.7), 7000)
+	for i := range metal {
+		metal[i] *= decay(float64(i)/sampleRate, tau)
+	}
+	return metal
+}
+
+func clap(r *rand.Rand) []float64 {
+	filtered := bandPass(noise(r, 0.35), 1200, 1.5)
+	for i := range filtered {
+		t := float64(i) / sampleRate
+		envelope := 0.7 * decay(t-0.02, 0.1) // tail, after the last burst
+		for _, burst := range []float64{0, 0.01, 0.02} {
+			if t >= burst {
+				envelope = math.Max(envelope, decay(t-burst, 0.005))
+			}
+		}
+		filtered[i] *= envelope
+	}
+	return filtered
+}
+
+func rim(r *rand.Rand) []float64 {
+	return render(0.1, func(t float64) float64 {
+		tone := 0.7*math.Sin(2*math.Pi*1700*t) + 0.5*math.Sin(2*math.Pi*500*t)
+		return tone*decay(t, 0.01) + 0.3*(2*r.Float64()-1)*decay(t, 0.002)
+	})
+}
+
+func tom(r *rand.Rand, freq float64) []float64 {
+	phase := 0.0
+	return render(0.35, func(t float64) float64 {
+		f := freq * (1 + 0.5*decay(t, 0.04))
+		phase += 2 * math.Pi * f / sampleRate
+		click := 0.2 * decay(t, 0.002) * (2*r.Float64() - 1)
+		return math.Sin(phase)*decay(t, 0.15) + click
+	})
+}
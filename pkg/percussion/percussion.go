@@ -0,0 +1,88 @@
+// Package percussion provides a small drum kit of audio samples embedded in the binary.
+//
+// The samples are synthesized by gen.go (run go generate to update them),
+// so they are free of any license restriction.
+package percussion
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ejuju/musigo/pkg/audio"
+)
+
+//go:generate go run gen.go
+
+//go:embed samples/*.wav
+var files embed.FS
+
+// Names of the samples of the kit.
+const (
+	Kick      = "kick"
+	Snare     = "snare"
+	ClosedHat = "closed-hat"
+	OpenHat   = "open-hat"
+	Clap      = "clap"
+	Rim       = "rim"
+	LowTom    = "low-tom"
+	MidTom    = "mid-tom"
+	HighTom   = "high-tom"
+)
+
+// samples holds the decoded samples of the kit, by name.
+var samples = map[string]*audio.Sample{}
+
+func init() {
+	entries, err := files.ReadDir("samples")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		sample, err := decode(path.Join("samples", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(entry.Name(), ".wav")
+		samples[name] = sample.WithName(name)
+	}
+}
+
+func decode(filepath string) (*audio.Sample, error) {
+	b, err := files.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := (&audio.WAVDecoder{}).Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath, err)
+	}
+	return sample, nil
+}
+
+// ErrUnknownSample means that there is no sample with the given name in the kit.
+var ErrUnknownSample = errors.New("unknown percussion sample")
+
+// Get returns the sample with the given name (for ex: percussion.Kick).
+// Samples can be played directly as a sound.Wave, or as an instrument with audio.NewSampler.
+func Get(name string) (*audio.Sample, error) {
+	sample, ok := samples[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSample, name)
+	}
+	return sample, nil
+}
+
+// Names returns the names of all the samples of the kit, in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
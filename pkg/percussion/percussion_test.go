@@ -0,0 +1,52 @@
+package percussion
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should provide all the samples of the kit", func(t *testing.T) {
+		names := []string{Kick, Snare, ClosedHat, OpenHat, Clap, Rim, LowTom, MidTom, HighTom}
+		if len(Names()) != len(names) {
+			t.Fatalf("want %d samples but got %v", len(names), Names())
+		}
+
+		for _, name := range names {
+			sample, err := Get(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sample.Name() != name {
+				t.Fatalf("want sample name %q but got %q", name, sample.Name())
+			}
+			if sample.SampleRate() != 44100 || sample.NumChannels() != 1 || sample.Len() == 0 {
+				t.Fatalf("%s: unexpected sample: %d Hz, %d channels, %d frames", name, sample.SampleRate(), sample.NumChannels(), sample.Len())
+			}
+
+			// samples should be audible and not clip
+			peak := 0.0
+			for _, v := range sample.Channel(0) {
+				peak = math.Max(peak, math.Abs(v))
+			}
+			if peak < 0.5 || peak > 1 {
+				t.Fatalf("%s: unexpected peak level %f", name, peak)
+			}
+
+			if _, err := sample.Value(10 * time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("Should return an error for unknown samples", func(t *testing.T) {
+		_, err := Get("cowbell")
+		if !errors.Is(err, ErrUnknownSample) {
+			t.Fatalf("want error %s but got %v", ErrUnknownSample, err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package sound
+
+import (
+	"fmt"
+	"time"
+)
+
+// FrameClock tracks the time of the frames rendered by a stateful effect,
+// that is an effect whose output depends on the previous frames (filters, delays, reverbs, etc.).
+//
+// Stateful effects are created for a sample rate and share the same contract:
+//   - they can be used on mono waves with Wrap, on stereo waves with WrapStereo (when they implement StereoEffect)
+//     and on processors with WrapProcessor, each call creating a new state;
+//   - waves must be rendered one frame after the other at their sample rate, so their Serial method always returns true;
+//   - when the time goes backwards (the wave is rendered again), their state is reset,
+//     and when it doesn't move, the previous output is returned;
+//   - the input processors must be rendered at the same sample rate;
+//   - an invalid sample rate is reported as an error at every frame;
+//   - their tail (echoes, reverberation, etc.) keeps playing after the end of the wrapped wave.
+//
+// The zero value is a clock that hasn't seen any frame yet.
+type FrameClock struct {
+	started bool
+	last    time.Duration
+}
+
+// ClockAction tells a stateful effect how to handle a frame, see FrameClock.Advance.
+type ClockAction int
+
+const (
+	ClockNext   ClockAction = iota // the time moved forward, the next frame must be rendered
+	ClockRepeat                    // same time as the previous frame, the previous output must be returned
+	ClockReset                     // first frame or the time went backwards, the state must be reset
+)
+
+// Advance moves the clock to the given time and reports how the effect must handle it.
+func (c *FrameClock) Advance(at time.Duration) ClockAction {
+	action := ClockNext
+	if !c.started || at < c.last {
+		action = ClockReset
+	} else if at == c.last {
+		action = ClockRepeat
+	}
+	c.started, c.last = true, at
+	return action
+}
+
+// checkSampleRate returns an error if the sample rate of a stateful effect is invalid.
+// Effects call it before advancing their clock, so an invalid effect fails at every frame.
+func checkSampleRate(sampleRate int) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d, sample rate must be positive", sampleRate)
+	}
+	return nil
+}
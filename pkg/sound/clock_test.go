@@ -0,0 +1,89 @@
+package sound
+
+import (
+	"testing"
+	"time"
+)
+
+// statefulEffect is an effect that can be used on waves and on processors (see FrameClock).
+type statefulEffect interface {
+	Effect
+	ProcessorEffect
+}
+
+func TestStatefulEffects(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 8000
+	tests := []struct {
+		name      string
+		newEffect func(sampleRate int) statefulEffect
+	}{
+		{name: "delay", newEffect: func(sampleRate int) statefulEffect {
+			return NewDelay(sampleRate, 30*time.Millisecond, 0.7, 0.5).WithDamping(100)
+		}},
+		{name: "reverb", newEffect: func(sampleRate int) statefulEffect {
+			return NewReverb(sampleRate, 0.8, 0.2, 0.3).WithPreDelay(10 * time.Millisecond)
+		}},
+		{name: "chorus", newEffect: func(sampleRate int) statefulEffect {
+			return NewChorus(sampleRate, 1.5, 0.8, 0.3, 0.5).WithVoices(2)
+		}},
+		{name: "flanger", newEffect: func(sampleRate int) statefulEffect {
+			return NewFlanger(sampleRate, 0.5, 1, -0.7, 0.5)
+		}},
+		{name: "phaser", newEffect: func(sampleRate int) statefulEffect {
+			return NewPhaser(sampleRate, 0.5, 1, 0.6, 0.5).WithStages(6)
+		}},
+		{name: "oversampled distortion", newEffect: func(sampleRate int) statefulEffect {
+			return NewSoftClip(5).WithOversampling(sampleRate, 2)
+		}},
+		{name: "bitcrusher", newEffect: func(sampleRate int) statefulEffect {
+			return NewBitcrusher(sampleRate, 4, 330)
+		}},
+	}
+
+	t.Run("Should return an error at every frame with an invalid sample rate", func(t *testing.T) {
+		for _, test := range tests {
+			effect := test.newEffect(0)
+			wave := effect.Wrap(impulseWave{})
+			var stereoWave StereoWave
+			if stereo, ok := effect.(StereoEffect); ok {
+				stereoWave = stereo.WrapStereo(UpMix(impulseWave{}))
+			}
+			for i := 0; i < 3; i++ {
+				at := FrameTime(0, i, sampleRate)
+				if _, err := wave.Value(at); err == nil {
+					t.Fatalf("%s: want an error at index %d", test.name, i)
+				}
+				if stereoWave == nil {
+					continue
+				}
+				if _, _, err := stereoWave.StereoValue(at); err == nil {
+					t.Fatalf("%s: want a stereo error at index %d", test.name, i)
+				}
+			}
+		}
+	})
+
+	t.Run("Should reset their state and process frames like waves", func(t *testing.T) {
+		source := NewSynthWave(SawTooth{}, 110)
+		for _, test := range tests {
+			effect := test.newEffect(sampleRate)
+			want := effect.Wrap(source)
+			got := NewProcessorWave(effect.WrapProcessor(NewWaveProcessor(source, sampleRate, 0)), sampleRate)
+			for pass := 0; pass < 2; pass++ {
+				for i := 0; i < sampleRate/4; i++ {
+					at := FrameTime(0, i, sampleRate)
+					w, _ := want.Value(at)
+					g, err := got.Value(at)
+					if err != nil {
+						t.Fatalf("%s: %s", test.name, err)
+					}
+					if g != w {
+						t.Fatalf("%s: unexpected frame value at index %d, want %f but got %f", test.name, i, w, g)
+					}
+				}
+			}
+		}
+	})
+}
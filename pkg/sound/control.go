@@ -0,0 +1,94 @@
+package sound
+
+import (
+	"time"
+
+	"github.com/ejuju/musigo/pkg/maths"
+	"github.com/ejuju/musigo/pkg/music"
+)
+
+// ControlWave is a wave used to control a parameter over time (for ex: the cutoff of a filter).
+// Like AmplitudeEnvelope, its value follows envelope segments,
+// but it plays only once and holds the end value of the last segment after the segments.
+type ControlWave struct {
+	fn         maths.InterpolationFunction
+	startValue float64
+	segments   []AmplitudeEnvelopeSegment
+}
+
+// NewControlWave creates a control wave that starts at startValue and goes through the segments.
+// If fn is nil, values are interpolated linearly.
+func NewControlWave(fn maths.InterpolationFunction, startValue float64, segments ...AmplitudeEnvelopeSegment) ControlWave {
+	if fn == nil {
+		fn = maths.LinearInterpolation{}
+	}
+	if len(segments) == 0 {
+		segments = []AmplitudeEnvelopeSegment{}
+	}
+	return ControlWave{fn: fn, startValue: startValue, segments: segments}
+}
+
+func (w ControlWave) Append(duration time.Duration, endValue float64) ControlWave {
+	w.segments = append(w.segments, AmplitudeEnvelopeSegment{Duration: duration, EndValue: endValue})
+	return w
+}
+
+func (w ControlWave) Value(at time.Duration) (float64, error) {
+	if at < 0 || len(w.segments) == 0 {
+		return w.startValue, nil
+	}
+	if val, ok := envelopeValue(w.fn, w.startValue, w.segments, at); ok {
+		return val, nil
+	}
+	return w.segments[len(w.segments)-1].EndValue, nil
+}
+
+// ConstantWave is a wave that always produces the same value.
+// It can be used for parameters that don't change over time.
+type ConstantWave struct {
+	value float64
+}
+
+func NewConstantWave(value float64) ConstantWave {
+	return ConstantWave{value: value}
+}
+
+func (w ConstantWave) Value(at time.Duration) (float64, error) {
+	return w.value, nil
+}
+
+// ScaledWave maps the values of a wave from the -1 to 1 range to the min to max range.
+// It is used to turn an oscillation into a control signal (for ex: a cutoff going from 200 to 2000 hertz).
+type ScaledWave struct {
+	wave     Wave
+	min, max float64
+}
+
+func NewScaledWave(wave Wave, min, max float64) ScaledWave {
+	return ScaledWave{wave: wave, min: min, max: max}
+}
+
+func (w ScaledWave) Serial() bool {
+	return IsSerial(w.wave)
+}
+
+func (w ScaledWave) Value(at time.Duration) (float64, error) {
+	val, err := w.wave.Value(at)
+	if err != nil {
+		return 0, err
+	}
+	return w.min + (val+1)/2*(w.max-w.min), nil
+}
+
+// NewLFO creates a low frequency oscillator that goes from min to max at the given frequency.
+// For ex: NewLFO(Sine{}, 0.5, 200, 2000) sweeps from 200 to 2000 and back every two seconds.
+func NewLFO(synth Synthesizer, freq, min, max float64) ScaledWave {
+	return NewScaledWave(NewSynthWave(synth, freq), min, max)
+}
+
+// BeatRate returns the frequency (in hertz) of a cycle that lasts the given number of beats at the given tempo,
+// it is used to sync the rate of LFOs and modulation effects to the tempo.
+// For ex: BeatRate(120, 4) is 0.5 hertz (one cycle per bar).
+func BeatRate(bpm music.BPM, beats float64) float64 {
+	return 1 / bpm.Time(beats).Seconds()
+}
@@ -0,0 +1,93 @@
+package sound
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+)
+
+func TestControlWave(t *testing.T) {
+	t.Parallel()
+
+	wave := NewControlWave(nil, 100).
+		Append(time.Second, 1000).
+		Append(time.Second, 500)
+
+	tests := []struct {
+		at   time.Duration
+		want float64
+	}{
+		{at: -time.Second, want: 100},
+		{at: 0, want: 100},
+		{at: 500 * time.Millisecond, want: 550},
+		{at: time.Second, want: 1000},
+		{at: 1500 * time.Millisecond, want: 750},
+		{at: 2 * time.Second, want: 500}, // holds the last value
+		{at: time.Minute, want: 500},
+	}
+
+	for i, test := range tests {
+		got, err := wave.Value(test.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-test.want) > 0.0000001 {
+			t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+		}
+	}
+
+	if got, _ := NewControlWave(nil, 3).Value(time.Second); got != 3 {
+		t.Fatalf("want the start value without segments but got %f", got)
+	}
+}
+
+func TestScaledWave(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wave Wave
+		want float64
+	}{
+		{wave: NewScaledWave(MockWave{}, 200, 2000), want: 2000},
+		{wave: NewScaledWave(NewConstantWave(-1), 200, 2000), want: 200},
+		{wave: NewScaledWave(SilentWave{}, 200, 2000), want: 1100},
+		{wave: NewLFO(Sine{}, 1, 0, 10), want: 5}, // sine starts at 0
+	}
+
+	for i, test := range tests {
+		got, err := test.wave.Value(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+		}
+	}
+
+	lfo := NewLFO(Sine{}, 1, 0, 10)
+	if got, _ := lfo.Value(250 * time.Millisecond); math.Abs(got-10) > 0.0000001 {
+		t.Fatalf("want the LFO to reach its max after a quarter cycle but got %f", got)
+	}
+}
+
+func TestBeatRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		bpm   music.BPM
+		beats float64
+		want  float64
+	}{
+		{bpm: 120, beats: 1, want: 2},
+		{bpm: 120, beats: 4, want: 0.5},
+		{bpm: 90, beats: 0.5, want: 3},
+	}
+
+	for i, test := range tests {
+		if got := BeatRate(test.bpm, test.beats); math.Abs(got-test.want) > 0.0000001 {
+			t.Fatalf("unexpected rate at index %d, want %f but got %f", i, test.want, got)
+		}
+	}
+}
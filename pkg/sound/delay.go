@@ -0,0 +1,233 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+)
+
+// Delay is an effect that repeats the wave after a delay, the repetitions fade out according to the feedback.
+// It is a stateful effect (see FrameClock), see PingPong to bounce the echoes between stereo channels.
+//
+// You must call NewDelay or NewBeatDelay to create a delay.
+type Delay struct {
+	wave       Wave
+	stereoWave StereoWave
+	sampleRate int
+	delayTime  time.Duration
+	feedback   float64 // from 0 (single echo) to 1 (endless echoes)
+	mix        float64 // from 0 (dry only) to 1 (wet only)
+	damping    float64 // cutoff of the low-pass filter in the feedback path, 0 to disable it
+	pingPong   bool
+	state      *delayState
+}
+
+// NewDelay creates a delay for waves rendered at the given sample rate.
+// The feedback goes from 0 (single echo) to 1 (endless echoes)
+// and the mix goes from 0 (only the input wave) to 1 (only the echoes).
+func NewDelay(sampleRate int, delayTime time.Duration, feedback, mix float64) Delay {
+	return Delay{
+		sampleRate: sampleRate,
+		delayTime:  delayTime,
+		feedback:   math.Max(0, math.Min(1, feedback)),
+		mix:        math.Max(0, math.Min(1, mix)),
+	}
+}
+
+// NewBeatDelay creates a delay synced to the tempo, for ex: 0.75 beats for a dotted eighth note delay.
+func NewBeatDelay(sampleRate int, bpm music.BPM, beats, feedback, mix float64) Delay {
+	return NewDelay(sampleRate, bpm.Time(beats), feedback, mix)
+}
+
+// WithDamping returns a copy of the delay with a low-pass filter in the feedback path,
+// so each echo is darker than the previous one (like tape or analog delays).
+func (d Delay) WithDamping(cutoff float64) Delay {
+	d.damping = cutoff
+	return d
+}
+
+// PingPong returns a copy of the delay whose echoes bounce between the left and right channels.
+// It only applies to stereo waves (see WrapStereo).
+func (d Delay) PingPong() Delay {
+	d.pingPong = true
+	return d
+}
+
+func (d Delay) Wrap(wave Wave) Wave {
+	d.wave = wave
+	d.state = &delayState{}
+	return d
+}
+
+// WrapStereo returns a stereo wave with the echoes of the stereo wave.
+// In ping-pong mode, the channels of the wave are mixed and the echoes alternate between the left and right channels.
+func (d Delay) WrapStereo(wave StereoWave) StereoWave {
+	d.stereoWave = wave
+	d.state = &delayState{}
+	return d
+}
+
+func (d Delay) Serial() bool {
+	return true
+}
+
+func (d Delay) Value(at time.Duration) (float64, error) {
+	if d.wave == nil || d.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(d.sampleRate); err != nil {
+		return 0, err
+	}
+	s := d.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.left.out, nil
+	case ClockReset:
+		s.reset(d)
+	}
+
+	x, err := d.wave.Value(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, err
+	}
+	return s.left.process(x, d), nil
+}
+
+func (d Delay) StereoValue(at time.Duration) (float64, float64, error) {
+	if d.stereoWave == nil || d.state == nil {
+		return 0, 0, ErrNoWave
+	}
+	if err := checkSampleRate(d.sampleRate); err != nil {
+		return 0, 0, err
+	}
+	s := d.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.left.out, s.right.out, nil
+	case ClockReset:
+		s.reset(d)
+	}
+
+	l, r, err := d.stereoWave.StereoValue(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, 0, err
+	}
+	if !d.pingPong {
+		return s.left.process(l, d), s.right.process(r, d), nil
+	}
+
+	// the input goes to the left line, the left echoes go to the right line and vice versa
+	dl, dr := s.left.read(), s.right.read()
+	s.left.write((l+r)/2 + d.feedback*dr)
+	s.right.write(d.feedback * dl)
+	s.left.out = (1-d.mix)*l + d.mix*dl
+	s.right.out = (1-d.mix)*r + d.mix*dr
+	return s.left.out, s.right.out, nil
+}
+
+// WrapProcessor returns a processor that adds the echoes to the frames of the input processor.
+func (d Delay) WrapProcessor(p Processor) Processor {
+	return &delayProcessor{input: p, params: d}
+}
+
+type delayProcessor struct {
+	input  Processor
+	params Delay
+	line   *delayLine
+}
+
+func (p *delayProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.params.sampleRate); err != nil {
+		return err
+	}
+	if p.line == nil {
+		p.line = newDelayLine(p.params)
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.line.process(x, p.params)
+	}
+	return nil
+}
+
+func (p *delayProcessor) Reset() {
+	p.input.Reset()
+	p.line = nil
+}
+
+type delayState struct {
+	clock       FrameClock
+	left, right *delayLine
+}
+
+func (s *delayState) reset(d Delay) {
+	s.left, s.right = newDelayLine(d), newDelayLine(d)
+}
+
+// delayLine is a circular buffer holding the delayed frames.
+type delayLine struct {
+	buf     []float64
+	pos     int
+	lowPass float64 // state of the damping filter
+	damping float64 // coefficient of the damping filter, 0 when disabled
+	out     float64 // last output
+}
+
+// newDelayLine creates the delay line of a delay with a valid sample rate.
+func newDelayLine(d Delay) *delayLine {
+	damping := 0.0
+	if d.damping > 0 {
+		damping = math.Exp(-2 * math.Pi * d.damping / float64(d.sampleRate))
+	}
+	return newRingLine(int(math.Round(d.delayTime.Seconds()*float64(d.sampleRate))), damping)
+}
+
+// newRingLine creates a delay line of the given number of frames (at least one)
+// with a damping filter coefficient from 0 (disabled) to 1.
+func newRingLine(length int, damping float64) *delayLine {
+	if length < 1 {
+		length = 1
+	}
+	return &delayLine{buf: make([]float64, length), damping: damping}
+}
+
+// read returns the oldest frame of the line, filtered by the damping filter.
+func (l *delayLine) read() float64 {
+	val := l.buf[l.pos]
+	if l.damping > 0 {
+		l.lowPass = val + l.damping*(l.lowPass-val)
+		return l.lowPass
+	}
+	return val
+}
+
+// tap returns the frame written the given number of frames ago (from 1 to the length of the line),
+// interpolating linearly between frames so the delay can be modulated smoothly.
+func (l *delayLine) tap(frames float64) float64 {
+	frames = math.Max(1, math.Min(float64(len(l.buf)), frames))
+	whole := int(frames)
+	val := l.buf[(l.pos-whole+len(l.buf))%len(l.buf)]
+	if progress := frames - float64(whole); progress > 0 {
+		next := l.buf[(l.pos-whole-1+len(l.buf))%len(l.buf)]
+		val += progress * (next - val)
+	}
+	return val
+}
+
+// write replaces the oldest frame of the line and moves to the next one.
+func (l *delayLine) write(val float64) {
+	l.buf[l.pos] = val
+	l.pos = (l.pos + 1) % len(l.buf)
+}
+
+// process feeds the frame to the line and returns the mix of the frame and of the echo.
+func (l *delayLine) process(x float64, d Delay) float64 {
+	delayed := l.read()
+	l.write(x + d.feedback*delayed)
+	l.out = (1-d.mix)*x + d.mix*delayed
+	return l.out
+}
@@ -0,0 +1,106 @@
+package sound
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/music"
+)
+
+// impulseWave produces 1 at the start and 0 the rest of the time.
+type impulseWave struct{}
+
+func (w impulseWave) Value(at time.Duration) (float64, error) {
+	if at == 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func TestDelay(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 1000
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ Effect = Delay{}
+		var _ StereoEffect = Delay{}
+		var _ ProcessorEffect = Delay{}
+	})
+
+	t.Run("Should repeat the wave with decreasing echoes", func(t *testing.T) {
+		wave := NewDelay(sampleRate, 100*time.Millisecond, 0.5, 0.5).Wrap(impulseWave{})
+
+		want := map[int]float64{0: 0.5, 100: 0.5, 200: 0.25, 300: 0.125}
+		for i := 0; i < 400; i++ {
+			got, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-want[i]) > 0.0000001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got)
+			}
+		}
+	})
+
+	t.Run("Should sync the delay time to the tempo", func(t *testing.T) {
+		wave := NewBeatDelay(sampleRate, music.BPM(120), 0.5, 0, 1).Wrap(impulseWave{})
+		for i := 0; i <= 250; i++ {
+			got, _ := wave.Value(FrameTime(0, i, sampleRate))
+			if (i == 250) != (got == 1) {
+				t.Fatalf("want a single echo at 250ms but got %f at index %d", got, i)
+			}
+		}
+	})
+
+	t.Run("Should damp the echoes", func(t *testing.T) {
+		wave := NewDelay(sampleRate, 100*time.Millisecond, 0.9, 1).WithDamping(50).Wrap(NewSynthWave(Sine{}, 200))
+		peak := 0.0
+		for i := 0; i < sampleRate; i++ {
+			got, _ := wave.Value(FrameTime(0, i, sampleRate))
+			if i > 500 {
+				peak = math.Max(peak, math.Abs(got))
+			}
+		}
+		if peak > 0.5 {
+			t.Fatalf("want the high frequencies to be damped, but got a peak of %f", peak)
+		}
+	})
+
+	t.Run("Should keep playing echoes after the end of the wave", func(t *testing.T) {
+		kick := NewKick()
+		kick.Decay = 50 * time.Millisecond
+		wave := NewDelay(sampleRate, 100*time.Millisecond, 0, 1).Wrap(kick)
+
+		echo := 0.0
+		for i := 0; i < 150; i++ {
+			got, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i >= 100 {
+				echo = math.Max(echo, math.Abs(got))
+			}
+		}
+		if echo == 0 {
+			t.Fatal("want the echo of the kick after its end")
+		}
+	})
+
+	t.Run("Should bounce echoes between channels in ping-pong mode", func(t *testing.T) {
+		wave := NewDelay(sampleRate, 100*time.Millisecond, 0.5, 1).PingPong().WrapStereo(UpMix(impulseWave{}))
+
+		type frame struct{ left, right float64 }
+		want := map[int]frame{100: {left: 1}, 200: {right: 0.5}, 300: {left: 0.25}}
+		for i := 0; i < 400; i++ {
+			left, right, err := wave.StereoValue(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(left-want[i].left) > 0.0000001 || math.Abs(right-want[i].right) > 0.0000001 {
+				t.Fatalf("unexpected frame at index %d, want %+v but got (%f, %f)", i, want[i], left, right)
+			}
+		}
+	})
+}
@@ -0,0 +1,351 @@
+package sound
+
+import (
+	"math"
+	"time"
+
+	"github.com/ejuju/musigo/pkg/maths"
+)
+
+const tubeBias = 0.3 // offset of the input of the tube saturation, makes the curve asymmetric
+
+// Q factors of the two biquads making a fourth-order Butterworth low-pass filter.
+var butterworthQ = [2]float64{0.5412, 1.3066}
+
+// Distortion is an effect that passes the values of the wave through a transfer curve,
+// after multiplying them by the drive (the higher the drive, the stronger the distortion).
+// It can be used on mono waves with Wrap and on processors.
+//
+// Distorting a wave creates harmonics above the Nyquist frequency that fold back into the audible range (aliasing),
+// see WithOversampling to reduce them.
+//
+// You must call NewDistortion or one of the curve constructors (for ex: NewSoftClip) to create a distortion.
+type Distortion struct {
+	wave         Wave
+	curve        func(x float64) float64
+	drive        float64
+	sampleRate   int
+	oversampling int // 1 when disabled
+	state        *distortionState
+}
+
+// NewDistortion creates a distortion with the given transfer curve (the identity if nil),
+// the curve receives the values of the wave multiplied by the drive.
+func NewDistortion(curve func(x float64) float64, drive float64) Distortion {
+	if curve == nil {
+		curve = func(x float64) float64 { return x }
+	}
+	return Distortion{curve: curve, drive: drive, oversampling: 1}
+}
+
+// NewSoftClip creates a distortion that smoothly saturates the wave (with a hyperbolic tangent).
+func NewSoftClip(drive float64) Distortion {
+	return NewDistortion(math.Tanh, drive)
+}
+
+// NewHardClip creates a distortion that cuts the values of the wave above 1 and below -1.
+func NewHardClip(drive float64) Distortion {
+	return NewDistortion(func(x float64) float64 {
+		return math.Max(-1, math.Min(1, x))
+	}, drive)
+}
+
+// NewFoldback creates a distortion that folds the values of the wave above 1 and below -1 back into the range,
+// which creates more and more harmonics as the drive increases.
+func NewFoldback(drive float64) Distortion {
+	return NewDistortion(func(x float64) float64 {
+		// triangle going through (-1, -1) and (1, 1), with a period of 4
+		m := math.Mod(x+1, 4)
+		if m < 0 {
+			m += 4
+		}
+		return 1 - math.Abs(m-2)
+	}, drive)
+}
+
+// NewTubeSaturation creates a distortion that saturates the positive and negative values of the wave differently,
+// like a tube amplifier, which adds even harmonics.
+// Because the curve is asymmetric, the distorted wave can have a DC offset (see HighPass filters to remove it).
+func NewTubeSaturation(drive float64) Distortion {
+	return NewDistortion(func(x float64) float64 {
+		return math.Tanh(x+tubeBias) - math.Tanh(tubeBias)
+	}, drive)
+}
+
+// NewWaveShaper creates a distortion whose transfer curve goes through the given points,
+// evenly spaced from -1 to 1 (for ex: -1, 0, 1 leaves the wave unchanged).
+// Values between the points are interpolated with fn (linear if nil),
+// values outside are clamped (so a drive above 1 pushes more of the wave to the ends of the curve).
+// With fewer than two points, the values are only multiplied by the drive.
+func NewWaveShaper(fn maths.InterpolationFunction, drive float64, points ...float64) Distortion {
+	if fn == nil {
+		fn = maths.LinearInterpolation{}
+	}
+	points = append([]float64(nil), points...)
+	return NewDistortion(func(x float64) float64 {
+		if len(points) < 2 {
+			return x
+		}
+		// position of x in the points
+		pos := (math.Max(-1, math.Min(1, x)) + 1) / 2 * float64(len(points)-1)
+		i := int(math.Min(pos, float64(len(points)-2)))
+		return fn.At(pos, float64(i), float64(i+1), points[i], points[i+1])
+	}, drive)
+}
+
+// WithOversampling returns a copy of the distortion that reduces aliasing
+// by distorting the wave at a higher sample rate (factor times the sample rate of the wave) and filtering it.
+// The filters make the distortion a stateful effect (see FrameClock).
+// A factor of 1 or less disables oversampling.
+func (d Distortion) WithOversampling(sampleRate, factor int) Distortion {
+	if factor < 1 {
+		factor = 1
+	}
+	d.sampleRate, d.oversampling = sampleRate, factor
+	return d
+}
+
+func (d Distortion) Wrap(wave Wave) Wave {
+	d.wave = wave
+	d.state = &distortionState{}
+	return d
+}
+
+// Serial returns true when oversampling is enabled, as the output then depends on the previous values.
+func (d Distortion) Serial() bool {
+	return d.oversampling > 1 || IsSerial(d.wave)
+}
+
+func (d Distortion) Value(at time.Duration) (float64, error) {
+	if d.wave == nil || d.state == nil {
+		return 0, ErrNoWave
+	}
+	if d.oversampling <= 1 {
+		val, err := d.wave.Value(at)
+		if err != nil {
+			return 0, err
+		}
+		return d.shape(val), nil
+	}
+
+	if err := checkSampleRate(d.sampleRate); err != nil {
+		return 0, err
+	}
+	s := d.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.out, nil
+	case ClockReset:
+		s.oversampler = newOversampler(d.sampleRate, d.oversampling)
+	}
+
+	val, err := d.wave.Value(at)
+	if err != nil {
+		return 0, err
+	}
+	s.out = s.oversampler.process(val, d.shape)
+	return s.out, nil
+}
+
+// WrapProcessor returns a processor that distorts the frames of the input processor.
+func (d Distortion) WrapProcessor(p Processor) Processor {
+	return &distortionProcessor{input: p, params: d}
+}
+
+// shape applies the drive and the transfer curve to the value.
+func (d Distortion) shape(x float64) float64 {
+	return d.curve(d.drive * x)
+}
+
+type distortionState struct {
+	clock       FrameClock
+	oversampler *oversampler
+	out         float64 // last output
+}
+
+type distortionProcessor struct {
+	input       Processor
+	params      Distortion
+	oversampler *oversampler
+}
+
+func (p *distortionProcessor) Process(buf []float64) error {
+	if p.params.oversampling > 1 {
+		if err := checkSampleRate(p.params.sampleRate); err != nil {
+			return err
+		}
+		if p.oversampler == nil {
+			p.oversampler = newOversampler(p.params.sampleRate, p.params.oversampling)
+		}
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		if p.oversampler == nil {
+			buf[i] = p.params.shape(x)
+			continue
+		}
+		buf[i] = p.oversampler.process(x, p.params.shape)
+	}
+	return nil
+}
+
+func (p *distortionProcessor) Reset() {
+	p.input.Reset()
+	p.oversampler = nil
+}
+
+// oversampler runs a function at a multiple of the sample rate:
+// each frame is followed by zeros and filtered (upsampling),
+// then the output of the function is filtered again and only one value per frame is kept (downsampling).
+// Both filters remove the frequencies above the Nyquist frequency of the original sample rate.
+type oversampler struct {
+	factor   int
+	up, down [2]biquad
+}
+
+func newOversampler(sampleRate, factor int) *oversampler {
+	o := &oversampler{factor: factor}
+	cutoff := 0.45 * float64(sampleRate)
+	for i, q := range butterworthQ {
+		o.up[i] = newBiquad(LowPass, sampleRate*factor, cutoff, q, 0)
+		o.down[i] = newBiquad(LowPass, sampleRate*factor, cutoff, q, 0)
+	}
+	return o
+}
+
+// process returns the frame passed through fn at the higher sample rate.
+func (o *oversampler) process(x float64, fn func(float64) float64) float64 {
+	out := 0.0
+	for i := 0; i < o.factor; i++ {
+		val := 0.0
+		if i == 0 {
+			val = x * float64(o.factor) // compensates for the energy lost with the zeros
+		}
+		for j := range o.up {
+			val = o.up[j].process(val)
+		}
+		val = fn(val)
+		for j := range o.down {
+			val = o.down[j].process(val)
+		}
+		out = val
+	}
+	return out
+}
+
+// Bitcrusher is an effect that reduces the resolution of the wave:
+// its values are rounded to a lower bit depth and held for several frames to lower its sample rate.
+// It is a stateful effect (see FrameClock): the frames are held from one call to the next.
+//
+// You must call NewBitcrusher to create a bitcrusher.
+type Bitcrusher struct {
+	wave       Wave
+	sampleRate int
+	bits       int     // 0 to keep the bit depth
+	rate       float64 // reduced sample rate in hertz, 0 to keep the sample rate
+	state      *bitcrusherState
+}
+
+// NewBitcrusher creates a bitcrusher for waves rendered at the given sample rate,
+// with the given bit depth (0 to keep the values) and reduced sample rate (0 to keep the sample rate).
+func NewBitcrusher(sampleRate, bits int, rate float64) Bitcrusher {
+	return Bitcrusher{sampleRate: sampleRate, bits: bits, rate: rate}
+}
+
+func (b Bitcrusher) Wrap(wave Wave) Wave {
+	b.wave = wave
+	b.state = &bitcrusherState{}
+	return b
+}
+
+func (b Bitcrusher) Serial() bool {
+	return true
+}
+
+func (b Bitcrusher) Value(at time.Duration) (float64, error) {
+	if b.wave == nil || b.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(b.sampleRate); err != nil {
+		return 0, err
+	}
+	s := b.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.crusher.held, nil
+	case ClockReset:
+		s.crusher = newCrusher()
+	}
+
+	val, err := b.wave.Value(at)
+	if err != nil {
+		return 0, err
+	}
+	return s.crusher.process(val, b), nil
+}
+
+// WrapProcessor returns a processor that crushes the frames of the input processor.
+func (b Bitcrusher) WrapProcessor(p Processor) Processor {
+	return &bitcrusherProcessor{input: p, params: b}
+}
+
+type bitcrusherState struct {
+	clock   FrameClock
+	crusher *crusher
+}
+
+type bitcrusherProcessor struct {
+	input   Processor
+	params  Bitcrusher
+	crusher *crusher
+}
+
+func (p *bitcrusherProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.params.sampleRate); err != nil {
+		return err
+	}
+	if p.crusher == nil {
+		p.crusher = newCrusher()
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.crusher.process(x, p.params)
+	}
+	return nil
+}
+
+func (p *bitcrusherProcessor) Reset() {
+	p.input.Reset()
+	p.crusher = nil
+}
+
+// crusher holds the state of a bitcrusher.
+type crusher struct {
+	phase float64 // progress towards the next held frame, a new frame is held when it reaches 1
+	held  float64 // last output
+}
+
+func newCrusher() *crusher {
+	return &crusher{phase: 1} // the first frame is always held
+}
+
+// process returns the held frame, after holding the input frame if it is time to.
+func (c *crusher) process(x float64, b Bitcrusher) float64 {
+	if b.rate <= 0 || b.rate >= float64(b.sampleRate) {
+		c.phase = 1
+	}
+	if c.phase >= 1 {
+		c.phase -= math.Floor(c.phase)
+		c.held = x
+		if b.bits > 0 {
+			levels := math.Pow(2, float64(b.bits-1))
+			c.held = math.Round(x*levels) / levels
+		}
+	}
+	c.phase += b.rate / float64(b.sampleRate)
+	return c.held
+}
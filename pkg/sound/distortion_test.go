@@ -0,0 +1,161 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+	"time"
+)
+
+func TestDistortion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ Effect = Distortion{}
+		var _ ProcessorEffect = Distortion{}
+	})
+
+	t.Run("Should pass the wave through the transfer curve", func(t *testing.T) {
+		tests := []struct {
+			distortion Distortion
+			input      float64
+			want       float64
+		}{
+			{distortion: NewSoftClip(2), input: 0.5, want: math.Tanh(1)},
+			{distortion: NewSoftClip(2), input: -0.5, want: -math.Tanh(1)},
+			{distortion: NewHardClip(2), input: 0.25, want: 0.5},
+			{distortion: NewHardClip(2), input: 0.75, want: 1},
+			{distortion: NewHardClip(2), input: -0.75, want: -1},
+			{distortion: NewFoldback(2), input: 0.25, want: 0.5},
+			{distortion: NewFoldback(2), input: 0.75, want: 0.5},   // 1.5 is folded back to 0.5
+			{distortion: NewFoldback(2), input: -0.75, want: -0.5}, // -1.5 is folded back to -0.5
+			{distortion: NewFoldback(2), input: 1.5, want: -1},     // 3 is folded back twice
+			{distortion: NewTubeSaturation(1), input: 0, want: 0},
+			{distortion: NewWaveShaper(nil, 1, 1, 0, 1), input: 0.5, want: 0.5}, // full-wave rectifier
+			{distortion: NewWaveShaper(nil, 1, 1, 0, 1), input: -0.5, want: 0.5},
+			{distortion: NewWaveShaper(nil, 1, 1, 0, 1), input: -2, want: 1}, // clamped
+			{distortion: NewWaveShaper(nil, 2, 1, 0, 1), input: 0.25, want: 0.5},
+			{distortion: NewWaveShaper(nil, 2, 1, 0, 1), input: 0.75, want: 1}, // driven into the clamp
+			{distortion: NewWaveShaper(nil, 1, 0, 1), input: -1, want: 0},
+			{distortion: NewWaveShaper(nil, 1), input: 0.3, want: 0.3}, // not enough points
+			{distortion: NewDistortion(nil, 2), input: 0.3, want: 0.6}, // identity curve
+		}
+
+		for i, test := range tests {
+			got, err := test.distortion.Wrap(NewConstantWave(test.input)).Value(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-test.want) > 0.0000001 {
+				t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should saturate positive and negative values differently with the tube saturation", func(t *testing.T) {
+		tube := NewTubeSaturation(2)
+		pos, _ := tube.Wrap(NewConstantWave(0.8)).Value(0)
+		neg, _ := tube.Wrap(NewConstantWave(-0.8)).Value(0)
+		if math.Abs(pos+neg) < 0.01 {
+			t.Fatalf("want an asymmetric curve, got %f and %f", pos, neg)
+		}
+	})
+
+	t.Run("Should reduce aliasing with oversampling", func(t *testing.T) {
+		sampleRate := 44100
+		// aliasAt returns the magnitude of the 10 kHz component of a clipped 3100 Hz sine,
+		// which is the alias of its 11th harmonic (34.1 kHz)
+		aliasAt := func(d Distortion) float64 {
+			wave := d.Wrap(NewSynthWave(Sine{}, 3100))
+			sum := complex(0, 0)
+			for i := 0; i < sampleRate; i++ {
+				val, err := wave.Value(time.Duration(i) * time.Second / time.Duration(sampleRate))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sum += complex(val, 0) * cmplx.Rect(1, -2*math.Pi*10000*float64(i)/float64(sampleRate))
+			}
+			return cmplx.Abs(sum) / float64(sampleRate)
+		}
+
+		plain := aliasAt(NewHardClip(10))
+		oversampled := aliasAt(NewHardClip(10).WithOversampling(sampleRate, 4))
+		if oversampled > plain/4 {
+			t.Fatalf("want less aliasing with oversampling, got %f without and %f with oversampling", plain, oversampled)
+		}
+	})
+
+	t.Run("Should return an error when no wave is wrapped", func(t *testing.T) {
+		if _, err := NewSoftClip(1).Value(0); !errors.Is(err, ErrNoWave) {
+			t.Fatalf("want %q but got %v", ErrNoWave, err)
+		}
+	})
+
+	t.Run("Should process frames like waves without oversampling", func(t *testing.T) {
+		sampleRate := 8000
+		source := NewSynthWave(Sine{}, 220)
+		distortion := NewFoldback(3)
+
+		want := distortion.Wrap(source)
+		got := NewProcessorWave(distortion.WrapProcessor(NewWaveProcessor(source, sampleRate, 0)), sampleRate)
+		for i := 0; i < sampleRate/4; i++ {
+			w, _ := want.Value(FrameTime(0, i, sampleRate))
+			g, err := got.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g != w {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, w, g)
+			}
+		}
+	})
+}
+
+func TestBitcrusher(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 1000
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ Effect = Bitcrusher{}
+		var _ ProcessorEffect = Bitcrusher{}
+	})
+
+	t.Run("Should reduce the bit depth", func(t *testing.T) {
+		tests := []struct {
+			bits        int
+			input, want float64
+		}{
+			{bits: 2, input: 0.3, want: 0.5},
+			{bits: 2, input: 0.2, want: 0},
+			{bits: 2, input: -0.8, want: -1},
+			{bits: 3, input: 0.3, want: 0.25},
+			{bits: 0, input: 0.3, want: 0.3},
+		}
+
+		for i, test := range tests {
+			got, err := NewBitcrusher(sampleRate, test.bits, 0).Wrap(NewConstantWave(test.input)).Value(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-test.want) > 0.0000001 {
+				t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should reduce the sample rate", func(t *testing.T) {
+		// each frame is held for 4 frames
+		wave := NewBitcrusher(sampleRate, 0, 250).Wrap(timeWave{})
+		for i := 0; i < 20; i++ {
+			got, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := FrameTime(0, i-i%4, sampleRate).Seconds(); got != want {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package sound
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Drum voices are one-shot waves inspired by analog drum machines.
+// They all share the same parameters:
+//   - Tune: main frequency of the voice, in hertz
+//   - Decay: time it takes for the voice to fade out, the wave ends after it
+//   - Tone: brightness of the voice, from 0 to 1
+//   - Snap: level of the attack transient or noise part, from 0 to 1
+//
+// Voices that use noise are created for a sample rate, they are stateful (see FrameClock):
+// their noise and filters are reset when the time goes backwards (so a voice can be rendered several times).
+
+// decayEnvelope returns an exponential envelope that reaches -60 dB after the decay time.
+func decayEnvelope(t float64, decay time.Duration) float64 {
+	return math.Exp(-6.9 * t / decay.Seconds())
+}
+
+// endOfDrum returns an error if the voice has ended.
+func endOfDrum(name string, at time.Duration, decay time.Duration) error {
+	if at < 0 || at >= decay {
+		return fmt.Errorf("%s has ended: %w", name, ErrEndOfWave)
+	}
+	return nil
+}
+
+// sweptSine returns the value of a sine whose frequency sweeps down exponentially from start to end.
+func sweptSine(t, start, end float64, sweepTime float64) float64 {
+	// the phase is the integral of the frequency so the voice can be rendered at any time
+	cycles := end*t + (start-end)*sweepTime*(1-math.Exp(-t/sweepTime))
+	val, _ := Sine{}.Synthesize(1, time.Duration(cycles*float64(time.Second)))
+	return val
+}
+
+// click returns a short square burst used for the attack of drum voices.
+func click(at time.Duration) float64 {
+	val, _ := Square{}.Synthesize(1500, at)
+	return val * math.Exp(-at.Seconds()/0.002)
+}
+
+// Kick is a bass drum made of a sine whose pitch sweeps down to its tuned frequency.
+//
+// Tone sets the depth of the pitch sweep and Snap the level of the attack click.
+type Kick struct {
+	Tune  float64
+	Decay time.Duration
+	Tone  float64
+	Snap  float64
+}
+
+// NewKick creates a kick with the default parameters of a classic 808 kick.
+func NewKick() Kick {
+	return Kick{Tune: 50, Decay: 500 * time.Millisecond, Tone: 0.5, Snap: 0.3}
+}
+
+func (w Kick) Value(at time.Duration) (float64, error) {
+	if err := endOfDrum("kick", at, w.Decay); err != nil {
+		return 0, err
+	}
+	t := at.Seconds()
+	body := sweptSine(t, w.Tune*(1+6*w.Tone), w.Tune, 0.03) * decayEnvelope(t, w.Decay)
+	return (body + w.Snap*click(at)) / (1 + w.Snap), nil
+}
+
+// Tom is a tom drum, it works like a kick with a shorter and shallower pitch sweep.
+//
+// Tone sets the depth of the pitch sweep and Snap the level of the attack click.
+type Tom struct {
+	Tune  float64
+	Decay time.Duration
+	Tone  float64
+	Snap  float64
+}
+
+// NewTom creates a tom tuned to the given frequency (for ex: 90 Hz for a low tom, 180 Hz for a high tom).
+func NewTom(tune float64) Tom {
+	return Tom{Tune: tune, Decay: 400 * time.Millisecond, Tone: 0.5, Snap: 0.2}
+}
+
+func (w Tom) Value(at time.Duration) (float64, error) {
+	if err := endOfDrum("tom", at, w.Decay); err != nil {
+		return 0, err
+	}
+	t := at.Seconds()
+	body := sweptSine(t, w.Tune*(1+w.Tone), w.Tune, 0.04) * decayEnvelope(t, w.Decay)
+	return (body + w.Snap*click(at)) / (1 + w.Snap), nil
+}
+
+// drumNoise produces the noise of drum voices and tracks the frames they render.
+type drumNoise struct {
+	seed       int64
+	sampleRate int
+	noise      RandomWideBandNoiseSynthesizer
+	clock      FrameClock
+	out        float64 // last output of the voice
+}
+
+func newDrumNoise(sampleRate int, seed int64) drumNoise {
+	return drumNoise{seed: seed, sampleRate: sampleRate}
+}
+
+// advance moves the clock of the voice to the given time and resets the noise when needed.
+// The voice must reset its filters on ClockReset and return out on ClockRepeat.
+func (n *drumNoise) advance(at time.Duration) (ClockAction, error) {
+	if err := checkSampleRate(n.sampleRate); err != nil {
+		return 0, err
+	}
+	action := n.clock.Advance(at)
+	if action == ClockReset {
+		n.noise = NewRandomWideBandNoiseSynthesizer(n.seed)
+	}
+	return action, nil
+}
+
+// next returns the next noise value.
+func (n *drumNoise) next() float64 {
+	val, _ := n.noise.Synthesize(0, 0)
+	return val
+}
+
+// dt returns the sampling period of the filters of the voice, in seconds.
+func (n *drumNoise) dt() float64 {
+	return 1 / float64(n.sampleRate)
+}
+
+// onePole is a one-pole filter state.
+type onePole struct {
+	x1, y1 float64
+}
+
+func (f *onePole) lowPass(x, cutoff, dt float64) float64 {
+	rc := 1 / (2 * math.Pi * cutoff)
+	f.y1 += dt / (rc + dt) * (x - f.y1)
+	f.x1 = x
+	return f.y1
+}
+
+func (f *onePole) highPass(x, cutoff, dt float64) float64 {
+	rc := 1 / (2 * math.Pi * cutoff)
+	f.y1 = rc / (rc + dt) * (f.y1 + x - f.x1)
+	f.x1 = x
+	return f.y1
+}
+
+// Snare is a snare drum made of two tuned sines and of high-passed noise for the snares.
+//
+// Tone sets the brightness of the noise and Snap the balance between the tone and the noise.
+type Snare struct {
+	Tune  float64
+	Decay time.Duration
+	Tone  float64
+	Snap  float64
+
+	noise  drumNoise
+	lp, hp onePole
+}
+
+// NewSnare creates a snare for the given sample rate, with the given noise seed
+// and the default parameters of a classic 808 snare.
+func NewSnare(sampleRate int, seed int64) *Snare {
+	return &Snare{Tune: 180, Decay: 250 * time.Millisecond, Tone: 0.5, Snap: 0.6, noise: newDrumNoise(sampleRate, seed)}
+}
+
+func (s *Snare) Serial() bool {
+	return true
+}
+
+func (s *Snare) Value(at time.Duration) (float64, error) {
+	if err := endOfDrum("snare", at, s.Decay); err != nil {
+		return 0, err
+	}
+	action, err := s.noise.advance(at)
+	if err != nil {
+		return 0, err
+	}
+	switch action {
+	case ClockRepeat:
+		return s.noise.out, nil
+	case ClockReset:
+		s.lp, s.hp = onePole{}, onePole{}
+	}
+	dt := s.noise.dt()
+	noise := s.hp.highPass(s.lp.lowPass(s.noise.next(), 3000+s.Tone*9000, dt), 1000, dt)
+
+	t := at.Seconds()
+	tone, _ := Sine{}.Synthesize(s.Tune, at)
+	overtone, _ := Sine{}.Synthesize(s.Tune*1.78, at)
+	body := (0.6*tone + 0.4*overtone) * decayEnvelope(t, s.Decay/3)
+	s.noise.out = (1-s.Snap)*body + s.Snap*noise*decayEnvelope(t, s.Decay)
+	return s.noise.out, nil
+}
+
+// hiHatRatios are the frequency ratios of the square oscillators of a hi-hat, relative to its tuned frequency.
+// They are inharmonic so that the cluster of oscillators sounds metallic.
+var hiHatRatios = []float64{1, 1.4826, 1.8002, 2.5459, 2.6303, 3.8966}
+
+// HiHat is a hi-hat cymbal made of a cluster of square oscillators and of noise, both high-passed.
+//
+// Tone sets the cutoff of the high-pass filter and Snap the level of the noise.
+type HiHat struct {
+	Tune  float64
+	Decay time.Duration
+	Tone  float64
+	Snap  float64
+
+	noise    drumNoise
+	hp1, hp2 onePole
+}
+
+// NewClosedHiHat creates a short hi-hat for the given sample rate, with the given noise seed.
+func NewClosedHiHat(sampleRate int, seed int64) *HiHat {
+	return &HiHat{Tune: 205, Decay: 80 * time.Millisecond, Tone: 0.5, Snap: 0.3, noise: newDrumNoise(sampleRate, seed)}
+}
+
+// NewOpenHiHat creates a long hi-hat for the given sample rate, with the given noise seed.
+func NewOpenHiHat(sampleRate int, seed int64) *HiHat {
+	return &HiHat{Tune: 205, Decay: 500 * time.Millisecond, Tone: 0.5, Snap: 0.3, noise: newDrumNoise(sampleRate, seed)}
+}
+
+func (h *HiHat) Serial() bool {
+	return true
+}
+
+func (h *HiHat) Value(at time.Duration) (float64, error) {
+	if err := endOfDrum("hi-hat", at, h.Decay); err != nil {
+		return 0, err
+	}
+	action, err := h.noise.advance(at)
+	if err != nil {
+		return 0, err
+	}
+	switch action {
+	case ClockRepeat:
+		return h.noise.out, nil
+	case ClockReset:
+		h.hp1, h.hp2 = onePole{}, onePole{}
+	}
+
+	metal := 0.0
+	for _, ratio := range hiHatRatios {
+		val, _ := Square{}.Synthesize(h.Tune*ratio, at)
+		metal += val
+	}
+	metal /= float64(len(hiHatRatios))
+
+	// two filters in series for a steeper slope
+	cutoff := 4000 + h.Tone*6000
+	dt := h.noise.dt()
+	val := h.hp2.highPass(h.hp1.highPass((1-h.Snap)*metal+h.Snap*h.noise.next(), cutoff, dt), cutoff, dt)
+	h.noise.out = val * decayEnvelope(at.Seconds(), h.Decay)
+	return h.noise.out, nil
+}
+
+// clapBursts are the start times of the noise bursts of a clap, in seconds.
+var clapBursts = []float64{0, 0.011, 0.023}
+
+// Clap is a hand clap made of a few quick bursts of band-passed noise followed by a longer tail.
+//
+// Tune sets the center frequency of the band-pass filter, Tone its bandwidth
+// and Snap the level of the bursts relative to the tail.
+type Clap struct {
+	Tune  float64
+	Decay time.Duration
+	Tone  float64
+	Snap  float64
+
+	noise  drumNoise
+	lp, hp onePole
+}
+
+// NewClap creates a clap for the given sample rate, with the given noise seed and default parameters.
+func NewClap(sampleRate int, seed int64) *Clap {
+	return &Clap{Tune: 1200, Decay: 300 * time.Millisecond, Tone: 0.5, Snap: 0.7, noise: newDrumNoise(sampleRate, seed)}
+}
+
+func (c *Clap) Serial() bool {
+	return true
+}
+
+func (c *Clap) Value(at time.Duration) (float64, error) {
+	if err := endOfDrum("clap", at, c.Decay); err != nil {
+		return 0, err
+	}
+	action, err := c.noise.advance(at)
+	if err != nil {
+		return 0, err
+	}
+	switch action {
+	case ClockRepeat:
+		return c.noise.out, nil
+	case ClockReset:
+		c.lp, c.hp = onePole{}, onePole{}
+	}
+	width := 1.5 + c.Tone*2 // ratio between the band edges and the center frequency
+	dt := c.noise.dt()
+	noise := c.hp.highPass(c.lp.lowPass(c.noise.next(), c.Tune*width, dt), c.Tune/width, dt)
+
+	t := at.Seconds()
+	last := clapBursts[len(clapBursts)-1]
+	envelope := 0.0
+	if t >= last {
+		envelope = (1 - c.Snap) * decayEnvelope(t-last, c.Decay)
+	}
+	for _, burst := range clapBursts {
+		if t >= burst {
+			envelope = math.Max(envelope, c.Snap*math.Exp(-(t-burst)/0.004))
+		}
+	}
+	c.noise.out = noise * envelope
+	return c.noise.out, nil
+}
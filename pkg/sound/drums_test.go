@@ -0,0 +1,123 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDrums(t *testing.T) {
+	t.Parallel()
+
+	newVoices := func() map[string]Wave {
+		return map[string]Wave{
+			"kick":       NewKick(),
+			"tom":        NewTom(120),
+			"snare":      NewSnare(44100, 1),
+			"closed hat": NewClosedHiHat(44100, 1),
+			"open hat":   NewOpenHiHat(44100, 1),
+			"clap":       NewClap(44100, 1),
+		}
+	}
+
+	// render returns the values of a wave at 44100 Hz until it ends.
+	render := func(t *testing.T, wave Wave) []float64 {
+		out := []float64{}
+		for i := 0; ; i++ {
+			val, err := wave.Value(time.Duration(i) * time.Second / 44100)
+			if errors.Is(err, ErrEndOfWave) {
+				return out
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, val)
+		}
+	}
+
+	t.Run("Should only be serial when using noise", func(t *testing.T) {
+		for name, voice := range newVoices() {
+			want := name != "kick" && name != "tom"
+			if got := IsSerial(voice); got != want {
+				t.Fatalf("%s: want serial to be %v but got %v", name, want, got)
+			}
+		}
+	})
+
+	t.Run("Should produce audible values within range and end after the decay", func(t *testing.T) {
+		for name, voice := range newVoices() {
+			values := render(t, voice)
+			if len(values) == 0 {
+				t.Fatalf("%s: want values but the wave ended right away", name)
+			}
+			peak := 0.0
+			for _, v := range values {
+				peak = math.Max(peak, math.Abs(v))
+			}
+			if peak < 0.05 || peak > 1 {
+				t.Fatalf("%s: unexpected peak level %f", name, peak)
+			}
+		}
+
+		_, err := NewKick().Value(time.Second)
+		if !errors.Is(err, ErrEndOfWave) {
+			t.Fatalf("want error %s after the decay but got %v", ErrEndOfWave, err)
+		}
+	})
+
+	t.Run("Should produce the same values when rendered again", func(t *testing.T) {
+		for name, voice := range newVoices() {
+			first, second := render(t, voice), render(t, voice)
+			for i := range first {
+				if first[i] != second[i] {
+					t.Fatalf("%s: unexpected frame value at index %d, want %f but got %f", name, i, first[i], second[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should return the same value when the time doesn't change", func(t *testing.T) {
+		snare := NewSnare(44100, 1)
+		wave := NewDualWave(snare, snare)
+		for i := 0; i < 100; i++ {
+			left, right, err := wave.StereoValue(time.Duration(i) * time.Second / 44100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if left != right {
+				t.Fatalf("unexpected frame at index %d, want the same value in both channels but got (%f, %f)", i, left, right)
+			}
+		}
+	})
+
+	t.Run("Should return an error at every frame with an invalid sample rate", func(t *testing.T) {
+		for name, voice := range map[string]Wave{"snare": NewSnare(0, 1), "hat": NewClosedHiHat(0, 1), "clap": NewClap(0, 1)} {
+			for i := 0; i < 3; i++ {
+				if _, err := voice.Value(time.Duration(i) * time.Millisecond); err == nil {
+					t.Fatalf("%s: want an error at index %d", name, i)
+				}
+			}
+		}
+	})
+
+	t.Run("Should sweep the pitch of the kick down to its tuned frequency", func(t *testing.T) {
+		kick := NewKick()
+		kick.Decay = 2 * time.Second
+		kick.Snap = 0
+
+		// count zero crossings during one second, long after the sweep has ended
+		crossings := 0
+		prev, _ := kick.Value(time.Second)
+		for i := 1; i <= 44100; i++ {
+			val, _ := kick.Value(time.Second + time.Duration(i)*time.Second/44100)
+			if (prev < 0) != (val < 0) {
+				crossings++
+			}
+			prev = val
+		}
+		if crossings < 98 || crossings > 102 {
+			t.Fatalf("want around 100 zero crossings (50 Hz) but got %d", crossings)
+		}
+	})
+}
@@ -50,35 +50,42 @@ func (w AmplitudeEnvelope) Wrap(wave Wave) Wave {
 	return w
 }
 
+func (w AmplitudeEnvelope) Serial() bool {
+	return IsSerial(w.wave)
+}
+
 func (w AmplitudeEnvelope) Value(at time.Duration) (float64, error) {
 	at = time.Duration(math.Mod(float64(at), float64(w.Duration())))
-	elapsed := time.Duration(0)
-	startValue := w.startValue
+	ampl, _ := envelopeValue(w.fn, w.startValue, w.segments, at)
 
-	ampl := 0.0
-	for _, segment := range w.segments {
+	val, err := w.wave.Value(at)
+	if err != nil {
+		return 0.0, fmt.Errorf("unable to get value from wave: %w", err)
+	}
+
+	return val * ampl, nil
+}
+
+// envelopeValue returns the value of the envelope segments at the given time,
+// it reports false if the time is not within the segments.
+func envelopeValue(fn maths.InterpolationFunction, startValue float64, segments []AmplitudeEnvelopeSegment, at time.Duration) (float64, bool) {
+	elapsed := time.Duration(0)
+	for _, segment := range segments {
 		// set val if current time is in this segment
 		if at >= elapsed && at < elapsed+segment.Duration {
-			ampl = w.fn.At(
+			return fn.At(
 				float64(at),
 				float64(elapsed),
 				float64(elapsed+segment.Duration),
 				startValue,
 				segment.EndValue,
-			)
-			break
+			), true
 		}
 
 		elapsed += segment.Duration
 		startValue = segment.EndValue
 	}
-
-	val, err := w.wave.Value(at)
-	if err != nil {
-		return 0.0, fmt.Errorf("unable to get value from wave: %w", err)
-	}
-
-	return val * ampl, nil
+	return 0, false
 }
 
 // A amplitude envelope segment represents one part of a control wave.
@@ -87,9 +94,101 @@ type AmplitudeEnvelopeSegment struct {
 	EndValue float64
 }
 
-// func NewTremoloEffect(phase time.Duration) Effect {
-// 	return NewAmplitudeEnvelope(NewControlWave(nil, 0, []*ControlWaveSegment{
-// 		{Duration: phase / 2, EndValue: 1.0},
-// 		{Duration: phase / 2},
-// 	}))
-// }
+// Tremolo is an effect that periodically modulates the amplitude of a wave.
+// Use BeatRate to sync the rate of the tremolo to the tempo.
+type Tremolo struct {
+	wave  Wave
+	shape Synthesizer
+	rate  float64
+	depth float64
+}
+
+// NewTremolo creates a tremolo whose amplitude follows the shape (Sine{} if nil) at the given rate (in hertz).
+// The depth goes from 0 (no modulation) to 1 (the amplitude goes down to 0 at each cycle).
+func NewTremolo(shape Synthesizer, rate, depth float64) Tremolo {
+	if shape == nil {
+		shape = Sine{}
+	}
+	return Tremolo{shape: shape, rate: rate, depth: math.Max(0, math.Min(1, depth))}
+}
+
+func (t Tremolo) Wrap(wave Wave) Wave {
+	t.wave = wave
+	return t
+}
+
+func (t Tremolo) Serial() bool {
+	return IsSerial(t.wave) || IsSerial(t.shape)
+}
+
+func (t Tremolo) Value(at time.Duration) (float64, error) {
+	if t.wave == nil {
+		return 0, ErrNoWave
+	}
+	mod, err := t.shape.Synthesize(t.rate, at)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get value from tremolo shape: %w", err)
+	}
+	val, err := t.wave.Value(at)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get value from wave: %w", err)
+	}
+	// the amplitude goes from 1 (when the shape is at 1) to 1-depth (when the shape is at -1)
+	return val * (1 - t.depth*(1-mod)/2), nil
+}
+
+// Vibrato is an effect that periodically modulates the pitch of a wave,
+// by warping the time at which the wave is played (slowing it down and speeding it up).
+// Use BeatRate to sync the rate of the vibrato to the tempo.
+type Vibrato struct {
+	wave  Wave
+	rate  float64
+	ratio float64 // pitch ratio at the top of the cycle, the pitch goes down to 1/ratio at the bottom
+}
+
+// NewVibrato creates a vibrato at the given rate (in hertz),
+// the pitch of the wave goes down and up by the given number of semitones (from 0 to 12).
+func NewVibrato(rate, semitones float64) Vibrato {
+	v := Vibrato{rate: rate, ratio: 1}
+	if rate > 0 {
+		v.ratio = math.Pow(2, math.Max(0, math.Min(12, semitones))/12)
+	}
+	return v
+}
+
+func (v Vibrato) Wrap(wave Wave) Wave {
+	v.wave = wave
+	return v
+}
+
+func (v Vibrato) Serial() bool {
+	return IsSerial(v.wave)
+}
+
+func (v Vibrato) Value(at time.Duration) (float64, error) {
+	if v.wave == nil {
+		return 0, ErrNoWave
+	}
+	if v.ratio == 1 {
+		return v.wave.Value(at)
+	}
+
+	// The pitch is multiplied by the speed of the warped time, which follows a half sine down to 1/ratio
+	// then a half sine up to ratio. The lower half is longer so that the mean speed is 1:
+	// the lag starts at 0 so the wave starts on time, it comes back to 0 after each cycle
+	// and the warped time never goes backwards.
+	up, down := v.ratio-1, 1-1/v.ratio
+	low := up / (up + down) // fraction of the cycle below the original pitch
+	period := 1 / v.rate
+	cycle := at.Seconds() * v.rate
+	phase := cycle - math.Floor(cycle)
+
+	var lag float64
+	if phase < low {
+		lag = down * low * period / math.Pi * (1 - math.Cos(math.Pi*phase/low))
+	} else {
+		high := 1 - low
+		lag = down*low*period/math.Pi*2 - up*high*period/math.Pi*(1-math.Cos(math.Pi*(phase-low)/high))
+	}
+	return v.wave.Value(at - time.Duration(lag*float64(time.Second)))
+}
@@ -0,0 +1,145 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// timeWave produces the time (in seconds) at which its value is requested.
+type timeWave struct{}
+
+func (w timeWave) Value(at time.Duration) (float64, error) {
+	return at.Seconds(), nil
+}
+
+// vibratoSpeed returns how fast the warped time of the vibrato goes at the given time, that is the pitch ratio.
+func vibratoSpeed(wave Wave, at time.Duration) float64 {
+	before, _ := wave.Value(at - time.Millisecond)
+	after, _ := wave.Value(at + time.Millisecond)
+	return (after - before) / 0.002
+}
+
+func TestTremolo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Effect interface", func(t *testing.T) {
+		var _ Effect = Tremolo{}
+	})
+
+	t.Run("Should modulate the amplitude of the wave", func(t *testing.T) {
+		tests := []struct {
+			tremolo Tremolo
+			at      time.Duration
+			want    float64
+		}{
+			{tremolo: NewTremolo(nil, 1, 1), at: 250 * time.Millisecond, want: 1},                     // sine at its max
+			{tremolo: NewTremolo(nil, 1, 1), at: 750 * time.Millisecond, want: 0},                     // sine at its min
+			{tremolo: NewTremolo(nil, 1, 0.5), at: 750 * time.Millisecond, want: 0.5},                 // half depth
+			{tremolo: NewTremolo(Square{}, 1, 0.5), at: 100 * time.Millisecond, want: 0.5},            // square low
+			{tremolo: NewTremolo(Square{}, 1, 0.5), at: 600 * time.Millisecond, want: 1},              // square high
+			{tremolo: NewTremolo(Square{}, BeatRate(120, 1), 1), at: 300 * time.Millisecond, want: 1}, // synced to the tempo
+			{tremolo: NewTremolo(Square{}, BeatRate(120, 1), 1), at: 600 * time.Millisecond, want: 0},
+		}
+
+		for i, test := range tests {
+			got, err := test.tremolo.Wrap(NewConstantWave(1)).Value(test.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-test.want) > 0.0000001 {
+				t.Fatalf("unexpected value at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should be serial when its shape is serial", func(t *testing.T) {
+		if IsSerial(NewTremolo(nil, 1, 1).Wrap(NewConstantWave(1))) {
+			t.Fatal("want a sine tremolo not to be serial")
+		}
+		if !IsSerial(NewTremolo(NewRandomWideBandNoiseSynthesizer(1), 1, 1).Wrap(NewConstantWave(1))) {
+			t.Fatal("want a noise tremolo to be serial")
+		}
+	})
+
+	t.Run("Should return an error when no wave is wrapped", func(t *testing.T) {
+		if _, err := NewTremolo(nil, 1, 1).Value(0); !errors.Is(err, ErrNoWave) {
+			t.Fatalf("want %q but got %v", ErrNoWave, err)
+		}
+	})
+}
+
+func TestVibrato(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Effect interface", func(t *testing.T) {
+		var _ Effect = Vibrato{}
+	})
+
+	t.Run("Should modulate the pitch of the wave", func(t *testing.T) {
+		// a cycle lasts 3 seconds, the pitch is below the original pitch for the first 2 seconds
+		wave := NewVibrato(1.0/3, 12).Wrap(timeWave{})
+
+		tests := []struct {
+			at   time.Duration
+			want float64
+		}{
+			{at: 1 * time.Second, want: 0.5},       // lowest pitch
+			{at: 2 * time.Second, want: 1},         // back to the original pitch
+			{at: 2500 * time.Millisecond, want: 2}, // highest pitch
+			{at: 3 * time.Second, want: 1},         // end of the cycle
+			{at: 4 * time.Second, want: 0.5},       // the modulation repeats
+		}
+
+		for i, test := range tests {
+			if got := vibratoSpeed(wave, test.at); math.Abs(got-test.want) > 0.001 {
+				t.Fatalf("unexpected pitch ratio at index %d, want %f but got %f", i, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should swing the pitch evenly up and down", func(t *testing.T) {
+		for _, semitones := range []float64{1, 7, 12} {
+			rate := 5.0
+			wave := NewVibrato(rate, semitones).Wrap(timeWave{})
+			lowest, highest := math.Inf(1), 0.0
+			for i := 0; i < 200; i++ {
+				speed := vibratoSpeed(wave, time.Duration(i)*time.Millisecond)
+				lowest, highest = math.Min(lowest, speed), math.Max(highest, speed)
+			}
+			ratio := math.Pow(2, semitones/12)
+			if math.Abs(lowest-1/ratio) > 0.001 || math.Abs(highest-ratio) > 0.001 {
+				t.Fatalf("%v semitones: want the pitch ratio to go from %f to %f but got %f to %f", semitones, 1/ratio, ratio, lowest, highest)
+			}
+			// the wave is back on time after each cycle
+			if got, _ := wave.Value(time.Second / time.Duration(rate)); math.Abs(got-1/rate) > 0.000001 {
+				t.Fatalf("%v semitones: want the wave on time after a cycle, want %f but got %f", semitones, 1/rate, got)
+			}
+		}
+	})
+
+	t.Run("Should start on time and never go backwards", func(t *testing.T) {
+		wave := NewVibrato(BeatRate(120, 0.25), 12).Wrap(timeWave{})
+		last := -1.0
+		for i := 0; i < 1000; i++ {
+			got, err := wave.Value(time.Duration(i) * time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i == 0 && got != 0 {
+				t.Fatalf("want the wave to start at 0 but got %f", got)
+			}
+			if got < last {
+				t.Fatalf("unexpected warped time at index %d, %f is before %f", i, got, last)
+			}
+			last = got
+		}
+	})
+
+	t.Run("Should return an error when no wave is wrapped", func(t *testing.T) {
+		if _, err := NewVibrato(5, 1).Value(0); !errors.Is(err, ErrNoWave) {
+			t.Fatalf("want %q but got %v", ErrNoWave, err)
+		}
+	})
+}
@@ -0,0 +1,344 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// FilterType defines the frequency response of a biquad filter.
+type FilterType int
+
+const (
+	LowPass   FilterType = iota // removes frequencies above the cutoff
+	HighPass                    // removes frequencies below the cutoff
+	BandPass                    // keeps frequencies around the cutoff
+	Notch                       // removes frequencies around the cutoff
+	Peaking                     // boosts or cuts frequencies around the cutoff (see BiquadFilter.WithGain)
+	LowShelf                    // boosts or cuts frequencies below the cutoff (see BiquadFilter.WithGain)
+	HighShelf                   // boosts or cuts frequencies above the cutoff (see BiquadFilter.WithGain)
+	AllPass                     // keeps all frequencies but changes their phase around the cutoff
+)
+
+// DefaultQ is the Q of a filter with a flat response and no resonance (1/√2).
+const DefaultQ = math.Sqrt2 / 2
+
+// BiquadFilter is an effect that filters frequencies of a wave,
+// using the biquad filters of the "Audio EQ Cookbook" by Robert Bristow-Johnson.
+//
+// Each call to Wrap creates a filter with its own state.
+//
+// You must call NewBiquadFilter to create a filter.
+type BiquadFilter struct {
+	wave       Wave
+	filterType FilterType
+	sampleRate int
+	cutoff     float64
+	q          float64
+	gain       float64 // in decibels
+	state      *biquadState
+}
+
+// NewBiquadFilter creates a filter for waves rendered at the given sample rate.
+// The cutoff is in hertz, the Q sets the resonance (or the bandwidth for band filters),
+// use DefaultQ for a filter without resonance (it is also used when q isn't positive).
+func NewBiquadFilter(filterType FilterType, sampleRate int, cutoff, q float64) BiquadFilter {
+	if q <= 0 {
+		q = DefaultQ
+	}
+	return BiquadFilter{filterType: filterType, sampleRate: sampleRate, cutoff: cutoff, q: q}
+}
+
+// WithGain returns a copy of the filter with the given gain (in decibels),
+// it is used by the Peaking, LowShelf and HighShelf filters.
+func (f BiquadFilter) WithGain(gain float64) BiquadFilter {
+	f.gain = gain
+	return f
+}
+
+func (f BiquadFilter) Wrap(wave Wave) Wave {
+	f.wave = wave
+	f.state = &biquadState{}
+	return f
+}
+
+func (f BiquadFilter) Serial() bool {
+	return true
+}
+
+// ErrNoWave means that an effect was used without wrapping a wave.
+var ErrNoWave = errors.New("no wave was wrapped by the effect")
+
+func (f BiquadFilter) Value(at time.Duration) (float64, error) {
+	if f.wave == nil || f.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(f.sampleRate); err != nil {
+		return 0, err
+	}
+
+	s := f.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.filter.y1, nil
+	case ClockReset:
+		s.filter = newBiquad(f.filterType, f.sampleRate, f.cutoff, f.q, f.gain)
+	}
+
+	val, err := f.wave.Value(at)
+	if err != nil {
+		return 0, err
+	}
+	return s.filter.process(val), nil
+}
+
+type biquadState struct {
+	filter biquad
+	clock  FrameClock
+}
+
+// biquad holds the normalized coefficients and the state of a biquad filter.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func newBiquad(filterType FilterType, sampleRate int, cutoff, q, gain float64) biquad {
+	w0 := 2 * math.Pi * cutoff / float64(sampleRate)
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * q)
+	a := math.Pow(10, gain/40)
+	beta := 2 * math.Sqrt(a) * alpha // used by shelf filters
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch filterType {
+	case LowPass:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case HighPass:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case BandPass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case Notch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case Peaking:
+		b0, b1, b2 = 1+alpha*a, -2*cos, 1-alpha*a
+		a0, a1, a2 = 1+alpha/a, -2*cos, 1-alpha/a
+	case LowShelf:
+		b0 = a * ((a + 1) - (a-1)*cos + beta)
+		b1 = 2 * a * ((a - 1) - (a+1)*cos)
+		b2 = a * ((a + 1) - (a-1)*cos - beta)
+		a0 = (a + 1) + (a-1)*cos + beta
+		a1 = -2 * ((a - 1) + (a+1)*cos)
+		a2 = (a + 1) + (a-1)*cos - beta
+	case HighShelf:
+		b0 = a * ((a + 1) + (a-1)*cos + beta)
+		b1 = -2 * a * ((a - 1) + (a+1)*cos)
+		b2 = a * ((a + 1) + (a-1)*cos - beta)
+		a0 = (a + 1) - (a-1)*cos + beta
+		a1 = 2 * ((a - 1) - (a+1)*cos)
+		a2 = (a + 1) - (a-1)*cos - beta
+	case AllPass:
+		b0, b1, b2 = 1-alpha, -2*cos, 1+alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	default:
+		// unknown filters let the wave through
+		b0, a0 = 1, 1
+	}
+
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// process filters the next value.
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// WrapProcessor returns a processor that filters the frames of the input processor.
+func (f BiquadFilter) WrapProcessor(p Processor) Processor {
+	return &biquadProcessor{input: p, params: f, filter: newBiquad(f.filterType, f.sampleRate, f.cutoff, f.q, f.gain)}
+}
+
+type biquadProcessor struct {
+	input  Processor
+	params BiquadFilter
+	filter biquad
+}
+
+func (p *biquadProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.params.sampleRate); err != nil {
+		return err
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.filter.process(x)
+	}
+	return nil
+}
+
+func (p *biquadProcessor) Reset() {
+	p.input.Reset()
+	p.filter = newBiquad(p.params.filterType, p.params.sampleRate, p.params.cutoff, p.params.q, p.params.gain)
+}
+
+// ModulatedFilter is a resonant state variable filter whose cutoff and resonance are controlled by waves,
+// for ex: a ControlWave for a filter envelope or an LFO for a filter sweep.
+// It supports the LowPass, HighPass, BandPass and Notch filter types, other types are used as LowPass.
+//
+// You must call NewModulatedFilter to create a filter.
+type ModulatedFilter struct {
+	wave       Wave
+	filterType FilterType
+	sampleRate int
+	cutoff     Wave // in hertz
+	resonance  Wave // from 0 (no resonance) to 1 (self-oscillation)
+	state      *svfState
+}
+
+// NewModulatedFilter creates a filter for waves rendered at the given sample rate.
+// Use NewConstantWave for a parameter that doesn't change over time.
+func NewModulatedFilter(filterType FilterType, sampleRate int, cutoff, resonance Wave) ModulatedFilter {
+	return ModulatedFilter{filterType: filterType, sampleRate: sampleRate, cutoff: cutoff, resonance: resonance}
+}
+
+func (f ModulatedFilter) Wrap(wave Wave) Wave {
+	f.wave = wave
+	f.state = &svfState{}
+	return f
+}
+
+func (f ModulatedFilter) Serial() bool {
+	return true
+}
+
+func (f ModulatedFilter) Value(at time.Duration) (float64, error) {
+	if f.wave == nil || f.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(f.sampleRate); err != nil {
+		return 0, err
+	}
+
+	s := f.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.out, nil
+	case ClockReset:
+		s.filter = svf{}
+	}
+
+	val, err := f.wave.Value(at)
+	if err != nil {
+		return 0, err
+	}
+	cutoff, resonance, err := f.params(at)
+	if err != nil {
+		return 0, err
+	}
+	s.out = s.filter.process(val, f.filterType, f.sampleRate, cutoff, resonance)
+	return s.out, nil
+}
+
+// params returns the cutoff and resonance at the given time.
+func (f ModulatedFilter) params(at time.Duration) (float64, float64, error) {
+	if f.cutoff == nil || f.resonance == nil {
+		return 0, 0, errors.New("cutoff and resonance waves must be defined")
+	}
+	cutoff, err := f.cutoff.Value(at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to get cutoff: %w", err)
+	}
+	resonance, err := f.resonance.Value(at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to get resonance: %w", err)
+	}
+	return cutoff, resonance, nil
+}
+
+// WrapProcessor returns a processor that filters the frames of the input processor.
+func (f ModulatedFilter) WrapProcessor(p Processor) Processor {
+	return &svfProcessor{input: p, params: f}
+}
+
+type svfProcessor struct {
+	input  Processor
+	params ModulatedFilter
+	filter svf
+	index  int // index of the next frame
+}
+
+func (p *svfProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.params.sampleRate); err != nil {
+		return err
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		cutoff, resonance, err := p.params.params(FrameTime(0, p.index, p.params.sampleRate))
+		if err != nil {
+			return err
+		}
+		buf[i] = p.filter.process(x, p.params.filterType, p.params.sampleRate, cutoff, resonance)
+		p.index++
+	}
+	return nil
+}
+
+func (p *svfProcessor) Reset() {
+	p.input.Reset()
+	p.filter = svf{}
+	p.index = 0
+}
+
+type svfState struct {
+	filter svf
+	out    float64
+	clock  FrameClock
+}
+
+// svf is the state of a state variable filter,
+// using the trapezoidal integration described by Andrew Simper (Cytomic)
+// which stays stable when the cutoff changes quickly.
+type svf struct {
+	ic1eq, ic2eq float64
+}
+
+func (f *svf) process(x float64, filterType FilterType, sampleRate int, cutoff, resonance float64) float64 {
+	// keep the parameters in a range where the filter is stable
+	nyquist := float64(sampleRate) / 2
+	cutoff = math.Max(1, math.Min(cutoff, 0.98*nyquist))
+	resonance = math.Max(0, math.Min(resonance, 0.99))
+
+	g := math.Tan(math.Pi * cutoff / float64(sampleRate))
+	k := 2 * (1 - resonance) // damping, 1/Q
+	a1 := 1 / (1 + g*(g+k))
+	a2 := g * a1
+	a3 := g * a2
+
+	v3 := x - f.ic2eq
+	v1 := a1*f.ic1eq + a2*v3
+	v2 := f.ic2eq + a2*f.ic1eq + a3*v3
+	f.ic1eq = 2*v1 - f.ic1eq
+	f.ic2eq = 2*v2 - f.ic2eq
+
+	switch filterType {
+	case HighPass:
+		return x - k*v1 - v2
+	case BandPass:
+		return v1
+	case Notch:
+		return x - k*v1
+	default:
+		return v2
+	}
+}
@@ -0,0 +1,206 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// rmsAt renders a wave at the given sample rate for a second
+// and returns the root mean square of the second half (once filters have settled).
+func rmsAt(t *testing.T, wave Wave, sampleRate int) float64 {
+	t.Helper()
+	sum := 0.0
+	for i := 0; i < sampleRate; i++ {
+		val, err := wave.Value(time.Duration(i) * time.Second / time.Duration(sampleRate))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= sampleRate/2 {
+			sum += val * val
+		}
+	}
+	return math.Sqrt(sum / float64(sampleRate-sampleRate/2))
+}
+
+func TestBiquadFilter(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 44100
+	sineRMS := math.Sqrt2 / 2
+
+	t.Run("Should implement the Effect interface", func(t *testing.T) {
+		var _ Effect = BiquadFilter{}
+	})
+
+	t.Run("Should apply the right gain to frequencies", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			filter   BiquadFilter
+			freq     float64
+			wantGain float64 // in decibels
+		}{
+			{name: "low-pass (pass band)", filter: NewBiquadFilter(LowPass, sampleRate, 1000, DefaultQ), freq: 100, wantGain: 0},
+			{name: "low-pass (cutoff)", filter: NewBiquadFilter(LowPass, sampleRate, 1000, DefaultQ), freq: 1000, wantGain: -3},
+			{name: "low-pass (stop band)", filter: NewBiquadFilter(LowPass, sampleRate, 1000, DefaultQ), freq: 10000, wantGain: -40},
+			{name: "low-pass (resonance)", filter: NewBiquadFilter(LowPass, sampleRate, 1000, 4), freq: 1000, wantGain: 12},
+			{name: "high-pass (pass band)", filter: NewBiquadFilter(HighPass, sampleRate, 1000, DefaultQ), freq: 10000, wantGain: 0},
+			{name: "high-pass (stop band)", filter: NewBiquadFilter(HighPass, sampleRate, 1000, DefaultQ), freq: 100, wantGain: -40},
+			{name: "band-pass (center)", filter: NewBiquadFilter(BandPass, sampleRate, 1000, 1), freq: 1000, wantGain: 0},
+			{name: "band-pass (away)", filter: NewBiquadFilter(BandPass, sampleRate, 1000, 1), freq: 100, wantGain: -20},
+			{name: "notch (center)", filter: NewBiquadFilter(Notch, sampleRate, 1000, 1), freq: 1000, wantGain: -60},
+			{name: "notch (away)", filter: NewBiquadFilter(Notch, sampleRate, 1000, 1), freq: 100, wantGain: 0},
+			{name: "peaking", filter: NewBiquadFilter(Peaking, sampleRate, 1000, 1).WithGain(6), freq: 1000, wantGain: 6},
+			{name: "low shelf", filter: NewBiquadFilter(LowShelf, sampleRate, 1000, DefaultQ).WithGain(-6), freq: 50, wantGain: -6},
+			{name: "high shelf", filter: NewBiquadFilter(HighShelf, sampleRate, 1000, DefaultQ).WithGain(6), freq: 15000, wantGain: 6},
+			{name: "all-pass", filter: NewBiquadFilter(AllPass, sampleRate, 1000, DefaultQ), freq: 1000, wantGain: 0},
+		}
+
+		for _, test := range tests {
+			wave := test.filter.Wrap(NewSynthWave(Sine{}, test.freq))
+			gain := 20 * math.Log10(rmsAt(t, wave, sampleRate)/sineRMS)
+			// stop bands only need to be attenuated enough
+			if test.wantGain <= -20 && gain <= test.wantGain {
+				continue
+			}
+			if math.Abs(gain-test.wantGain) > 0.5 {
+				t.Fatalf("%s: want a gain of %.1f dB at %.0f Hz but got %.1f dB", test.name, test.wantGain, test.freq, gain)
+			}
+		}
+	})
+
+	t.Run("Should reset its state when the time goes backwards", func(t *testing.T) {
+		wave := NewBiquadFilter(LowPass, sampleRate, 500, 2).Wrap(NewSynthWave(SawTooth{}, 220))
+		first, second := rmsAt(t, wave, sampleRate), rmsAt(t, wave, sampleRate)
+		if first != second {
+			t.Fatalf("want the same output when rendered again, got RMS %f and %f", first, second)
+		}
+		first, _ = wave.Value(0)
+		second, _ = wave.Value(0)
+		if first != second {
+			t.Fatalf("want the same value when called twice at the same time, got %f and %f", first, second)
+		}
+	})
+
+	t.Run("Should use DefaultQ when q isn't positive", func(t *testing.T) {
+		for _, q := range []float64{0, -1} {
+			got := NewBiquadFilter(LowPass, sampleRate, 1000, q).Wrap(NewSynthWave(SawTooth{}, 220))
+			want := NewBiquadFilter(LowPass, sampleRate, 1000, DefaultQ).Wrap(NewSynthWave(SawTooth{}, 220))
+			for i := 0; i < 1000; i++ {
+				at := time.Duration(i) * time.Second / time.Duration(sampleRate)
+				g, _ := got.Value(at)
+				w, _ := want.Value(at)
+				if g != w {
+					t.Fatalf("q=%f: unexpected frame value at index %d, want %f but got %f", q, i, w, g)
+				}
+			}
+		}
+	})
+
+	t.Run("Should create a new state each time a wave is wrapped", func(t *testing.T) {
+		filter := NewBiquadFilter(LowPass, sampleRate, 500, DefaultQ)
+		a := filter.Wrap(MockWave{})
+		b := filter.Wrap(SilentWave{})
+		for i := 0; i < 100; i++ {
+			at := time.Duration(i) * time.Second / time.Duration(sampleRate)
+			a.Value(at)
+			if val, _ := b.Value(at); val != 0 {
+				t.Fatalf("want silence from the second filter but got %f", val)
+			}
+		}
+	})
+
+	t.Run("Should report errors", func(t *testing.T) {
+		if _, err := NewBiquadFilter(LowPass, sampleRate, 500, DefaultQ).Value(0); !errors.Is(err, ErrNoWave) {
+			t.Fatalf("want error %s but got %v", ErrNoWave, err)
+		}
+		if _, err := NewBiquadFilter(LowPass, 0, 500, DefaultQ).Wrap(MockWave{}).Value(0); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+		processor := NewBiquadFilter(LowPass, 0, 500, DefaultQ).WrapProcessor(NewWaveProcessor(MockWave{}, 8000, 0))
+		if err := processor.Process(make([]float64, 16)); err == nil {
+			t.Fatal("want an error for an invalid sample rate when processing frames")
+		}
+		if !IsSerial(NewBiquadFilter(LowPass, sampleRate, 500, DefaultQ).Wrap(MockWave{})) {
+			t.Fatal("want filters to be serial")
+		}
+	})
+}
+
+func TestModulatedFilter(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 44100
+	sineRMS := math.Sqrt2 / 2
+
+	t.Run("Should implement the Effect and ProcessorEffect interfaces", func(t *testing.T) {
+		var _ Effect = ModulatedFilter{}
+		var _ ProcessorEffect = ModulatedFilter{}
+	})
+
+	t.Run("Should filter frequencies around the cutoff", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			filter    ModulatedFilter
+			freq      float64
+			wantGain  float64 // in decibels
+			tolerance float64
+		}{
+			{name: "low-pass (pass band)", filter: NewModulatedFilter(LowPass, sampleRate, NewConstantWave(1000), NewConstantWave(0.3)), freq: 100, wantGain: 0, tolerance: 0.5},
+			{name: "low-pass (stop band)", filter: NewModulatedFilter(LowPass, sampleRate, NewConstantWave(1000), NewConstantWave(0.3)), freq: 10000, wantGain: -40, tolerance: 6},
+			{name: "low-pass (resonance)", filter: NewModulatedFilter(LowPass, sampleRate, NewConstantWave(1000), NewConstantWave(0.9)), freq: 1000, wantGain: 14, tolerance: 0.5},
+			{name: "high-pass (stop band)", filter: NewModulatedFilter(HighPass, sampleRate, NewConstantWave(1000), NewConstantWave(0.3)), freq: 100, wantGain: -40, tolerance: 6},
+			{name: "band-pass (center)", filter: NewModulatedFilter(BandPass, sampleRate, NewConstantWave(1000), NewConstantWave(0)), freq: 1000, wantGain: -6, tolerance: 0.5},
+		}
+
+		for _, test := range tests {
+			wave := test.filter.Wrap(NewSynthWave(Sine{}, test.freq))
+			gain := 20 * math.Log10(rmsAt(t, wave, sampleRate)/sineRMS)
+			if math.Abs(gain-test.wantGain) > test.tolerance {
+				t.Fatalf("%s: want a gain of %.1f dB at %.0f Hz but got %.1f dB", test.name, test.wantGain, test.freq, gain)
+			}
+		}
+	})
+
+	t.Run("Should follow the cutoff over time", func(t *testing.T) {
+		// the cutoff sweeps up from 100 Hz to 10 kHz during the first half, and stays at 10 kHz
+		cutoff := NewControlWave(nil, 100).Append(500*time.Millisecond, 10000)
+		wave := NewModulatedFilter(LowPass, sampleRate, cutoff, NewConstantWave(0)).Wrap(NewSynthWave(Sine{}, 2000))
+
+		early, late := 0.0, 0.0
+		for i := 0; i < sampleRate; i++ {
+			val, err := wave.Value(time.Duration(i) * time.Second / time.Duration(sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i < sampleRate/50 { // cutoff below 500 Hz
+				early = math.Max(early, math.Abs(val))
+			} else if i > sampleRate/2 {
+				late = math.Max(late, math.Abs(val))
+			}
+		}
+		if early > 0.15 || late < 0.9 {
+			t.Fatalf("want the sine to be filtered out at first and let through at the end, got peaks %f and %f", early, late)
+		}
+	})
+
+	t.Run("Should filter processors like waves", func(t *testing.T) {
+		source := NewSynthWave(SawTooth{}, 110)
+		filter := NewModulatedFilter(LowPass, sampleRate, NewLFO(Sine{}, 2, 200, 4000), NewConstantWave(0.7))
+
+		want := filter.Wrap(source)
+		got := NewProcessorWave(filter.WrapProcessor(NewWaveProcessor(source, sampleRate, 0)), sampleRate)
+		for i := 0; i < sampleRate/4; i++ {
+			at := time.Duration(i) * time.Second / time.Duration(sampleRate)
+			w, _ := want.Value(at)
+			g, err := got.Value(at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g != w {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, w, g)
+			}
+		}
+	})
+}
@@ -0,0 +1,394 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	chorusDelay         = 20 * time.Millisecond // delay of the chorus voices around which the delay is modulated
+	chorusSweep         = 8 * time.Millisecond  // modulation of the delay of the chorus voices at full depth
+	chorusRateSpread    = 0.1                   // difference of rate between two chorus voices, relative to the rate
+	flangerDelay        = 500 * time.Microsecond
+	flangerSweep        = 5 * time.Millisecond
+	phaserCenterFreq    = 800.0 // frequency of the all-pass stages of the phaser when the LFO is at 0
+	phaserSweep         = 2.0   // octaves swept above and below the center frequency at full depth
+	maxModFeedback      = 0.95
+	modStereoPhase      = 0.25 // offset of the LFO of the right channel, in cycles
+	defaultChorusVoices = 3
+	defaultPhaserStages = 4
+)
+
+// Chorus is an effect that thickens the wave by mixing it with several copies (voices)
+// whose delays drift slowly around 20ms, so each voice is slightly detuned,
+// like several instruments playing the same part.
+// It is a stateful effect (see FrameClock).
+// On stereo waves, the voices of the right channel drift a quarter of a cycle ahead of the left ones.
+//
+// You must call NewChorus to create a chorus.
+type Chorus struct {
+	modulation
+	voices int
+}
+
+// NewChorus creates a chorus for waves rendered at the given sample rate.
+// The rate (in hertz, see BeatRate to sync it to the tempo) sets the speed of the modulation,
+// the depth (from 0 to 1) its amount, the feedback (from -0.95 to 0.95) feeds the voices back into the delay
+// and the mix goes from 0 (only the input wave) to 1 (only the voices).
+func NewChorus(sampleRate int, rate, depth, feedback, mix float64) Chorus {
+	return Chorus{modulation: newModulation(sampleRate, rate, depth, feedback, mix), voices: defaultChorusVoices}
+}
+
+// WithVoices returns a copy of the chorus with the given number of voices (at least one).
+// The voices are modulated at slightly different rates and out of phase.
+func (c Chorus) WithVoices(voices int) Chorus {
+	if voices < 1 {
+		voices = 1
+	}
+	c.voices = voices
+	return c
+}
+
+func (c Chorus) Wrap(wave Wave) Wave {
+	c.wave = wave
+	c.state = &modulationState{}
+	return c
+}
+
+func (c Chorus) WrapStereo(wave StereoWave) StereoWave {
+	c.stereoWave = wave
+	c.state = &modulationState{}
+	return c
+}
+
+func (c Chorus) Value(at time.Duration) (float64, error) {
+	return c.value(at, c.newChannel)
+}
+
+func (c Chorus) StereoValue(at time.Duration) (float64, float64, error) {
+	return c.stereoValue(at, c.newChannel)
+}
+
+// WrapProcessor returns a processor that adds the chorus to the frames of the input processor.
+func (c Chorus) WrapProcessor(p Processor) Processor {
+	return &modulationProcessor{input: p, sampleRate: c.sampleRate, newChannel: c.newChannel}
+}
+
+func (c Chorus) newChannel(phase float64) modulationChannel {
+	ch := &chorusChannel{
+		params: c,
+		line:   newRingLine(c.frames(chorusDelay+chorusSweep)+2, 0),
+	}
+	for i := 0; i < c.voices; i++ {
+		rate := c.rate * (1 + chorusRateSpread*float64(i))
+		ch.lfos = append(ch.lfos, newLFO(c.sampleRate, rate, phase+float64(i)/float64(c.voices)))
+	}
+	return ch
+}
+
+type chorusChannel struct {
+	params Chorus
+	line   *delayLine
+	lfos   []lfo
+}
+
+func (ch *chorusChannel) process(x float64) float64 {
+	p := ch.params
+	wet := 0.0
+	for i := range ch.lfos {
+		delay := chorusDelay.Seconds() + p.depth*chorusSweep.Seconds()*ch.lfos[i].next()
+		wet += ch.line.tap(delay * float64(p.sampleRate))
+	}
+	wet /= float64(len(ch.lfos))
+	ch.line.write(x + p.feedback*wet)
+	return (1-p.mix)*x + p.mix*wet
+}
+
+// Flanger is an effect that mixes the wave with a copy delayed by a few milliseconds at most.
+// Sweeping the delay moves harmonically spaced notches up and down the spectrum (the "jet plane" sound),
+// the feedback turns them into resonant peaks.
+// Like the chorus, it widens stereo waves by sweeping the channels out of phase.
+//
+// You must call NewFlanger to create a flanger.
+type Flanger struct {
+	modulation
+}
+
+// NewFlanger creates a flanger for waves rendered at the given sample rate.
+// The rate (in hertz, see BeatRate to sync it to the tempo) sets the speed of the sweep,
+// the depth (from 0 to 1) its amount, the feedback (from -0.95 to 0.95) its resonance
+// and the mix goes from 0 (only the input wave) to 1 (only the delayed wave).
+func NewFlanger(sampleRate int, rate, depth, feedback, mix float64) Flanger {
+	return Flanger{modulation: newModulation(sampleRate, rate, depth, feedback, mix)}
+}
+
+func (f Flanger) Wrap(wave Wave) Wave {
+	f.wave = wave
+	f.state = &modulationState{}
+	return f
+}
+
+func (f Flanger) WrapStereo(wave StereoWave) StereoWave {
+	f.stereoWave = wave
+	f.state = &modulationState{}
+	return f
+}
+
+func (f Flanger) Value(at time.Duration) (float64, error) {
+	return f.value(at, f.newChannel)
+}
+
+func (f Flanger) StereoValue(at time.Duration) (float64, float64, error) {
+	return f.stereoValue(at, f.newChannel)
+}
+
+// WrapProcessor returns a processor that adds the flanger to the frames of the input processor.
+func (f Flanger) WrapProcessor(p Processor) Processor {
+	return &modulationProcessor{input: p, sampleRate: f.sampleRate, newChannel: f.newChannel}
+}
+
+func (f Flanger) newChannel(phase float64) modulationChannel {
+	return &flangerChannel{
+		params: f,
+		line:   newRingLine(f.frames(flangerDelay+flangerSweep)+2, 0),
+		lfo:    newLFO(f.sampleRate, f.rate, phase),
+	}
+}
+
+type flangerChannel struct {
+	params Flanger
+	line   *delayLine
+	lfo    lfo
+}
+
+func (ch *flangerChannel) process(x float64) float64 {
+	p := ch.params
+	// the delay goes from its minimum (when the LFO is at -1) to the full sweep (when the LFO is at 1)
+	delay := flangerDelay.Seconds() + p.depth*flangerSweep.Seconds()*(ch.lfo.next()+1)/2
+	wet := ch.line.tap(delay * float64(p.sampleRate))
+	ch.line.write(x + p.feedback*wet)
+	return (1-p.mix)*x + p.mix*wet
+}
+
+// Phaser is an effect that mixes the wave with a copy going through a chain of all-pass filters
+// swept around 800Hz: the frequencies shifted out of phase cancel out.
+// Unlike the flanger's, its few notches aren't harmonically spaced, which gives a softer, vocal sweep.
+// Its stereo channels are swept out of phase too.
+//
+// You must call NewPhaser to create a phaser.
+type Phaser struct {
+	modulation
+	stages int
+}
+
+// NewPhaser creates a phaser for waves rendered at the given sample rate.
+// The rate (in hertz, see BeatRate to sync it to the tempo) sets the speed of the sweep,
+// the depth (from 0 to 1) its amount, the feedback (from -0.95 to 0.95) its resonance
+// and the mix goes from 0 (only the input wave) to 1 (only the filtered wave), the notches are deepest at 0.5.
+func NewPhaser(sampleRate int, rate, depth, feedback, mix float64) Phaser {
+	return Phaser{modulation: newModulation(sampleRate, rate, depth, feedback, mix), stages: defaultPhaserStages}
+}
+
+// WithStages returns a copy of the phaser with the given number of all-pass stages (at least one),
+// every two stages add a notch.
+func (p Phaser) WithStages(stages int) Phaser {
+	if stages < 1 {
+		stages = 1
+	}
+	p.stages = stages
+	return p
+}
+
+func (p Phaser) Wrap(wave Wave) Wave {
+	p.wave = wave
+	p.state = &modulationState{}
+	return p
+}
+
+func (p Phaser) WrapStereo(wave StereoWave) StereoWave {
+	p.stereoWave = wave
+	p.state = &modulationState{}
+	return p
+}
+
+func (p Phaser) Value(at time.Duration) (float64, error) {
+	return p.value(at, p.newChannel)
+}
+
+func (p Phaser) StereoValue(at time.Duration) (float64, float64, error) {
+	return p.stereoValue(at, p.newChannel)
+}
+
+// WrapProcessor returns a processor that adds the phaser to the frames of the input processor.
+func (p Phaser) WrapProcessor(input Processor) Processor {
+	return &modulationProcessor{input: input, sampleRate: p.sampleRate, newChannel: p.newChannel}
+}
+
+func (p Phaser) newChannel(phase float64) modulationChannel {
+	return &phaserChannel{
+		params: p,
+		stages: make([]float64, p.stages),
+		lfo:    newLFO(p.sampleRate, p.rate, phase),
+	}
+}
+
+type phaserChannel struct {
+	params Phaser
+	stages []float64 // state of each first-order all-pass stage
+	lfo    lfo
+	wet    float64 // last output of the stages, fed back to their input
+}
+
+func (ch *phaserChannel) process(x float64) float64 {
+	p := ch.params
+	freq := phaserCenterFreq * math.Pow(2, phaserSweep*p.depth*ch.lfo.next())
+	freq = math.Min(freq, 0.45*float64(p.sampleRate))
+	tan := math.Tan(math.Pi * freq / float64(p.sampleRate))
+	coef := (tan - 1) / (tan + 1)
+
+	wet := x + p.feedback*ch.wet
+	for i, state := range ch.stages {
+		out := coef*wet + state
+		ch.stages[i] = wet - coef*out
+		wet = out
+	}
+	ch.wet = wet
+	return (1-p.mix)*x + p.mix*wet
+}
+
+// modulation holds the parameters and the state shared by the modulation effects.
+type modulation struct {
+	wave       Wave
+	stereoWave StereoWave
+	sampleRate int
+	rate       float64 // frequency of the LFO in hertz
+	depth      float64 // from 0 to 1
+	feedback   float64 // from -maxModFeedback to maxModFeedback
+	mix        float64 // from 0 (dry only) to 1 (wet only)
+	state      *modulationState
+}
+
+func newModulation(sampleRate int, rate, depth, feedback, mix float64) modulation {
+	return modulation{
+		sampleRate: sampleRate,
+		rate:       rate,
+		depth:      math.Max(0, math.Min(1, depth)),
+		feedback:   math.Max(-maxModFeedback, math.Min(maxModFeedback, feedback)),
+		mix:        math.Max(0, math.Min(1, mix)),
+	}
+}
+
+func (m modulation) Serial() bool {
+	return true
+}
+
+// frames returns the number of frames in the given duration.
+func (m modulation) frames(d time.Duration) int {
+	return int(math.Ceil(d.Seconds() * float64(m.sampleRate)))
+}
+
+// value renders the frame of the wrapped wave at the given time with a channel created by newChannel.
+func (m modulation) value(at time.Duration, newChannel func(phase float64) modulationChannel) (float64, error) {
+	if m.wave == nil || m.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(m.sampleRate); err != nil {
+		return 0, err
+	}
+	s := m.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.leftOut, nil
+	case ClockReset:
+		s.left = newChannel(0)
+	}
+
+	x, err := m.wave.Value(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, err
+	}
+	s.leftOut = s.left.process(x)
+	return s.leftOut, nil
+}
+
+// stereoValue renders the frame of the wrapped stereo wave at the given time with channels created by newChannel.
+func (m modulation) stereoValue(at time.Duration, newChannel func(phase float64) modulationChannel) (float64, float64, error) {
+	if m.stereoWave == nil || m.state == nil {
+		return 0, 0, ErrNoWave
+	}
+	if err := checkSampleRate(m.sampleRate); err != nil {
+		return 0, 0, err
+	}
+	s := m.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.leftOut, s.rightOut, nil
+	case ClockReset:
+		s.left, s.right = newChannel(0), newChannel(modStereoPhase)
+	}
+
+	l, r, err := m.stereoWave.StereoValue(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, 0, err
+	}
+	s.leftOut, s.rightOut = s.left.process(l), s.right.process(r)
+	return s.leftOut, s.rightOut, nil
+}
+
+// modulationChannel processes the frames of one channel of a modulation effect.
+type modulationChannel interface {
+	process(x float64) float64
+}
+
+type modulationState struct {
+	clock             FrameClock
+	left, right       modulationChannel
+	leftOut, rightOut float64 // last outputs
+}
+
+type modulationProcessor struct {
+	input      Processor
+	sampleRate int
+	newChannel func(phase float64) modulationChannel
+	channel    modulationChannel
+}
+
+func (p *modulationProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.sampleRate); err != nil {
+		return err
+	}
+	if p.channel == nil {
+		p.channel = p.newChannel(0)
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.channel.process(x)
+	}
+	return nil
+}
+
+func (p *modulationProcessor) Reset() {
+	p.input.Reset()
+	p.channel = nil
+}
+
+// lfo is a sine low frequency oscillator advancing one frame at a time.
+type lfo struct {
+	phase float64 // in cycles, from 0 to 1
+	step  float64 // cycles per frame
+}
+
+func newLFO(sampleRate int, rate, phase float64) lfo {
+	return lfo{phase: phase - math.Floor(phase), step: rate / float64(sampleRate)}
+}
+
+// next returns the value of the LFO (from -1 to 1) and moves to the next frame.
+func (o *lfo) next() float64 {
+	val := math.Sin(2 * math.Pi * o.phase)
+	o.phase += o.step
+	o.phase -= math.Floor(o.phase)
+	return val
+}
@@ -0,0 +1,105 @@
+package sound
+
+import (
+	"math"
+	"testing"
+)
+
+func TestModulationEffects(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 8000
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ Effect = Chorus{}
+		var _ StereoEffect = Chorus{}
+		var _ ProcessorEffect = Chorus{}
+		var _ Effect = Flanger{}
+		var _ StereoEffect = Flanger{}
+		var _ ProcessorEffect = Flanger{}
+		var _ Effect = Phaser{}
+		var _ StereoEffect = Phaser{}
+		var _ ProcessorEffect = Phaser{}
+	})
+
+	t.Run("Should delay the voices of the chorus", func(t *testing.T) {
+		// without modulation, all the voices are delayed by the same time
+		wave := NewChorus(sampleRate, 1, 0, 0, 1).Wrap(impulseWave{})
+		delay := int(chorusDelay.Seconds() * float64(sampleRate))
+		for i := 0; i <= delay; i++ {
+			got, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := 0.0
+			if i == delay {
+				want = 1
+			}
+			if math.Abs(got-want) > 0.0000001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want, got)
+			}
+		}
+	})
+
+	t.Run("Should modulate the delay of the chorus", func(t *testing.T) {
+		static := NewChorus(sampleRate, 2, 0, 0, 1).Wrap(NewSynthWave(Sine{}, 440))
+		modulated := NewChorus(sampleRate, 2, 1, 0, 1).Wrap(NewSynthWave(Sine{}, 440))
+		diff := 0.0
+		for i := 0; i < sampleRate/2; i++ {
+			s, _ := static.Value(FrameTime(0, i, sampleRate))
+			m, _ := modulated.Value(FrameTime(0, i, sampleRate))
+			diff = math.Max(diff, math.Abs(s-m))
+		}
+		if diff < 0.1 {
+			t.Fatalf("want the modulation to change the wave, got a max difference of %f", diff)
+		}
+	})
+
+	t.Run("Should feed the flanger back into its delay", func(t *testing.T) {
+		wave := NewFlanger(sampleRate, 1, 0, 0.5, 1).Wrap(impulseWave{})
+		delay := int(flangerDelay.Seconds() * float64(sampleRate))
+		want := map[int]float64{delay: 1, 2 * delay: 0.5, 3 * delay: 0.25}
+		for i := 0; i < 4*delay; i++ {
+			got, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-want[i]) > 0.0000001 {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want[i], got)
+			}
+		}
+	})
+
+	t.Run("Should create notches with the phaser", func(t *testing.T) {
+		sampleRate := 44100
+		// four stages shift the phase by 180 degrees at about 0.41 times the center frequency
+		// and by 360 degrees at the center frequency
+		notch := rmsAt(t, NewPhaser(sampleRate, 1, 0, 0, 0.5).Wrap(NewSynthWave(Sine{}, 0.414*phaserCenterFreq)), sampleRate)
+		peak := rmsAt(t, NewPhaser(sampleRate, 1, 0, 0, 0.5).Wrap(NewSynthWave(Sine{}, phaserCenterFreq)), sampleRate)
+		if notch > 0.05 || peak < 0.65 {
+			t.Fatalf("want a notch below the center frequency, got RMS values of %f (notch) and %f (center)", notch, peak)
+		}
+	})
+
+	t.Run("Should modulate the stereo channels out of phase", func(t *testing.T) {
+		effects := []StereoEffect{
+			NewChorus(sampleRate, 2, 1, 0, 0.5),
+			NewFlanger(sampleRate, 2, 1, 0.5, 0.5),
+			NewPhaser(sampleRate, 2, 1, 0.5, 0.5),
+		}
+		for i, effect := range effects {
+			wave := effect.WrapStereo(UpMix(NewSynthWave(SawTooth{}, 220)))
+			diff := 0.0
+			for j := 0; j < sampleRate/2; j++ {
+				left, right, err := wave.StereoValue(FrameTime(0, j, sampleRate))
+				if err != nil {
+					t.Fatal(err)
+				}
+				diff = math.Max(diff, math.Abs(left-right))
+			}
+			if diff == 0 {
+				t.Fatalf("want the left and right channels to be different for the effect at index %d", i)
+			}
+		}
+	})
+}
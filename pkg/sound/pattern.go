@@ -24,6 +24,15 @@ type PatternSegment struct {
 	Wave     Wave
 }
 
+func (p Pattern) Serial() bool {
+	for _, segment := range p.segments {
+		if IsSerial(segment.Wave) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p Pattern) Value(x time.Duration) (float64, error) {
 	x = time.Duration(math.Mod(float64(x), float64(p.Duration())))
 
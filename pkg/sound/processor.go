@@ -0,0 +1,138 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Processor renders mono frames block by block, at a sample rate that is set when it is created.
+//
+// Unlike waves, processors are stateful: each call to Process continues where the previous one stopped.
+// This allows recursive algorithms (filters, delays, reverbs, etc.) that depend on previous frames.
+type Processor interface {
+	// Process fills the buffer with the next frames.
+	Process(buf []float64) error
+	// Reset restarts the processor from its first frame.
+	Reset()
+}
+
+// ProcessorEffect is implemented by effects that can process the frames of a processor.
+type ProcessorEffect interface {
+	WrapProcessor(Processor) Processor
+}
+
+// WaveProcessor allows conversion from Wave to Processor,
+// it renders the values of the wave at each frame time.
+//
+// You must call NewWaveProcessor to create a wave processor.
+type WaveProcessor struct {
+	wave        Wave
+	sampleRate  int
+	startOffset time.Duration
+	index       int // index of the next frame
+}
+
+// NewWaveProcessor creates a processor that renders the wave at the given sample rate, starting at startOffset.
+func NewWaveProcessor(wave Wave, sampleRate int, startOffset time.Duration) *WaveProcessor {
+	return &WaveProcessor{wave: wave, sampleRate: sampleRate, startOffset: startOffset}
+}
+
+func (p *WaveProcessor) Process(buf []float64) error {
+	if p.wave == nil {
+		return errors.New("wave is not defined, unable to process frames")
+	}
+	if p.sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d, sample rate should be positive", p.sampleRate)
+	}
+	for i := range buf {
+		val, err := p.wave.Value(FrameTime(p.startOffset, p.index, p.sampleRate))
+		if err != nil {
+			return err
+		}
+		buf[i] = val
+		p.index++
+	}
+	return nil
+}
+
+func (p *WaveProcessor) Reset() {
+	p.index = 0
+}
+
+// processorWaveBlockSize is the number of frames processed at once by a ProcessorWave.
+const processorWaveBlockSize = 256
+
+// ProcessorWave allows conversion from Processor to Wave.
+// Frames are processed in blocks as the wave is played,
+// the processor is reset when the time goes backwards and frames are skipped when the time jumps forward.
+//
+// You must call NewProcessorWave to create a processor wave.
+type ProcessorWave struct {
+	processor  Processor
+	sampleRate int
+	block      []float64
+	blockStart int // index of the first frame of the block
+	blockLen   int // number of processed frames in the block
+}
+
+// NewProcessorWave creates a wave that plays the frames of the processor, which are rendered at the given sample rate.
+func NewProcessorWave(p Processor, sampleRate int) *ProcessorWave {
+	return &ProcessorWave{processor: p, sampleRate: sampleRate, block: make([]float64, processorWaveBlockSize)}
+}
+
+// Serial always returns true as the processor must process frames in order.
+func (w *ProcessorWave) Serial() bool {
+	return true
+}
+
+func (w *ProcessorWave) Value(at time.Duration) (float64, error) {
+	if w.sampleRate <= 0 {
+		return 0, fmt.Errorf("invalid sample rate: %d, sample rate should be positive", w.sampleRate)
+	}
+	index := FrameIndex(at, w.sampleRate)
+	if index < 0 {
+		return 0, nil
+	}
+
+	if index < w.blockStart {
+		w.processor.Reset()
+		w.blockStart, w.blockLen = 0, 0
+	}
+	for index >= w.blockStart+w.blockLen {
+		w.blockStart += w.blockLen
+		w.blockLen = 0
+		if err := w.processor.Process(w.block); err != nil {
+			return 0, err
+		}
+		w.blockLen = len(w.block)
+	}
+	return w.block[index-w.blockStart], nil
+}
+
+// FrameTime returns the time of the frame with the given index: startOffset + index * time.Second / sampleRate.
+// It is computed from the frame index (with nanosecond precision) so that frame times don't drift.
+func FrameTime(startOffset time.Duration, index, sampleRate int) time.Duration {
+	// split the computation in whole seconds and the rest to avoid overflows
+	whole := time.Duration(index/sampleRate) * time.Second
+	rest := time.Duration(index%sampleRate) * time.Second / time.Duration(sampleRate)
+	return startOffset + whole + rest
+}
+
+// FrameIndex returns the index of the frame playing at the given time,
+// that is the index of the last frame whose time (as returned by FrameTime with no offset) is not after the given time.
+// It returns -1 for negative times.
+func FrameIndex(at time.Duration, sampleRate int) int {
+	if at < 0 {
+		return -1
+	}
+	// floor(((at + 1) * sampleRate - 1) / second), split in whole seconds and the rest to avoid overflows
+	x := at + 1
+	rate := int64(sampleRate)
+	whole := int64(x/time.Second) * rate
+	rest := int64(x%time.Second) * rate
+	if rest == 0 {
+		return int(whole - 1)
+	}
+	return int(whole + (rest-1)/int64(time.Second))
+}
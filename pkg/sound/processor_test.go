@@ -0,0 +1,136 @@
+package sound
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaveProcessor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement the Processor interface", func(t *testing.T) {
+		var _ Processor = &WaveProcessor{}
+	})
+
+	t.Run("Should render the wave block by block", func(t *testing.T) {
+		wave := NewSynthWave(SawTooth{}, 3)
+		p := NewWaveProcessor(wave, 100, 10*time.Millisecond)
+
+		buf := make([]float64, 7)
+		for block := 0; block < 3; block++ {
+			if err := p.Process(buf); err != nil {
+				t.Fatal(err)
+			}
+			for i, got := range buf {
+				index := block*len(buf) + i
+				want, _ := wave.Value(10*time.Millisecond + time.Duration(index)*10*time.Millisecond)
+				if got != want {
+					t.Fatalf("unexpected frame value at index %d, want %f but got %f", index, want, got)
+				}
+			}
+		}
+
+		p.Reset()
+		p.Process(buf)
+		if want, _ := wave.Value(10 * time.Millisecond); buf[0] != want {
+			t.Fatalf("want the first frame (%f) after a reset but got %f", want, buf[0])
+		}
+	})
+
+	t.Run("Should validate its inputs", func(t *testing.T) {
+		if err := NewWaveProcessor(nil, 100, 0).Process(make([]float64, 1)); err == nil {
+			t.Fatal("want an error for a nil wave")
+		}
+		if err := NewWaveProcessor(MockWave{}, 0, 0).Process(make([]float64, 1)); err == nil {
+			t.Fatal("want an error for an invalid sample rate")
+		}
+	})
+}
+
+func TestProcessorWave(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should play the frames of the processor", func(t *testing.T) {
+		sampleRate := 1000
+		wave := NewSynthWave(Sine{}, 7)
+		pw := NewProcessorWave(NewWaveProcessor(wave, sampleRate, 0), sampleRate)
+
+		// play forward (with skipped frames), then backward
+		for _, index := range []int{0, 1, 2, 300, 301, 999, 5, 1000, 0} {
+			at := time.Duration(index) * time.Second / time.Duration(sampleRate)
+			want, _ := wave.Value(at)
+			got, err := pw.Value(at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", index, want, got)
+			}
+		}
+		if !IsSerial(pw) {
+			t.Fatal("want processor waves to be serial")
+		}
+	})
+
+	t.Run("Should filter frames like the filter effect", func(t *testing.T) {
+		sampleRate := 8000
+		source := NewSynthWave(SawTooth{}, 110)
+		filter := NewBiquadFilter(LowPass, sampleRate, 800, 2)
+
+		want := filter.Wrap(source)
+		got := NewProcessorWave(filter.WrapProcessor(NewWaveProcessor(source, sampleRate, 0)), sampleRate)
+		for pass := 0; pass < 2; pass++ { // the second pass checks that both are reset
+			for i := 0; i < sampleRate; i++ {
+				at := time.Duration(i) * time.Second / time.Duration(sampleRate)
+				w, _ := want.Value(at)
+				g, err := got.Value(at)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g != w {
+					t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, w, g)
+				}
+			}
+		}
+	})
+}
+
+func TestFrameTiming(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should compute frame times from the frame index", func(t *testing.T) {
+		tests := []struct {
+			startOffset time.Duration
+			index       int
+			sampleRate  int
+			want        time.Duration
+		}{
+			{startOffset: 0, index: 0, sampleRate: 44100, want: 0},
+			{startOffset: 0, index: 1, sampleRate: 44100, want: 22675},
+			{startOffset: 0, index: 44100, sampleRate: 44100, want: time.Second},
+			{startOffset: 0, index: 44100*3600 + 1, sampleRate: 44100, want: time.Hour + 22675},
+			{startOffset: time.Second, index: 2, sampleRate: 4, want: 1500 * time.Millisecond},
+		}
+
+		for _, test := range tests {
+			got := FrameTime(test.startOffset, test.index, test.sampleRate)
+			if got != test.want {
+				t.Fatalf("frame %d at %d Hz: want %s but got %s", test.index, test.sampleRate, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Should find the frame index of frame times", func(t *testing.T) {
+		for _, sampleRate := range []int{1, 3, 22050, 44100, 48000, 96000} {
+			for _, index := range []int{0, 1, 2, sampleRate - 1, sampleRate, 10*sampleRate + 7, 3600 * sampleRate} {
+				at := FrameTime(0, index, sampleRate)
+				if got := FrameIndex(at, sampleRate); got != index {
+					t.Fatalf("%d Hz: want frame %d at %s but got %d", sampleRate, index, at, got)
+				}
+				if got := FrameIndex(FrameTime(0, index+1, sampleRate)-1, sampleRate); got != index {
+					t.Fatalf("%d Hz: want frame %d just before the next frame but got %d", sampleRate, index, got)
+				}
+			}
+		}
+	})
+}
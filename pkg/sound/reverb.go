@@ -0,0 +1,214 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Tuning of the Freeverb algorithm by Jezar at Dreampoint, in frames at 44100 Hz.
+var (
+	reverbCombTunings    = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllPassTunings = []int{556, 441, 341, 225}
+)
+
+const (
+	reverbStereoSpread = 23    // difference (in frames at 44100 Hz) between the left and right networks
+	reverbInputGain    = 0.015 // keeps the sum of the comb filters in range
+	reverbWetGain      = 3
+	reverbAllPassGain  = 0.5
+)
+
+// Reverb is an effect that simulates the reflections of a room,
+// using the Freeverb algorithm (parallel comb filters followed by all-pass filters).
+// It is a stateful effect (see FrameClock).
+//
+// You must call NewReverb to create a reverb.
+type Reverb struct {
+	wave       Wave
+	stereoWave StereoWave
+	sampleRate int
+	roomSize   float64 // from 0 to 1
+	damping    float64 // from 0 to 1
+	mix        float64 // from 0 (dry only) to 1 (wet only)
+	preDelay   time.Duration
+	state      *reverbState
+}
+
+// NewReverb creates a reverb for waves rendered at the given sample rate.
+// The room size (from 0 to 1) sets the length of the tail, the damping (from 0 to 1) absorbs its high frequencies
+// and the mix goes from 0 (only the input wave) to 1 (only the reverb).
+func NewReverb(sampleRate int, roomSize, damping, mix float64) Reverb {
+	return Reverb{
+		sampleRate: sampleRate,
+		roomSize:   math.Max(0, math.Min(1, roomSize)),
+		damping:    math.Max(0, math.Min(1, damping)),
+		mix:        math.Max(0, math.Min(1, mix)),
+	}
+}
+
+// WithPreDelay returns a copy of the reverb that starts after the given delay,
+// which separates the reverb from the input wave (larger rooms have longer pre-delays).
+func (r Reverb) WithPreDelay(preDelay time.Duration) Reverb {
+	r.preDelay = preDelay
+	return r
+}
+
+func (r Reverb) Wrap(wave Wave) Wave {
+	r.wave = wave
+	r.state = &reverbState{}
+	return r
+}
+
+// WrapStereo returns a stereo wave with the reverb of the stereo wave,
+// the left and right channels use slightly different reflections for a wider sound.
+func (r Reverb) WrapStereo(wave StereoWave) StereoWave {
+	r.stereoWave = wave
+	r.state = &reverbState{}
+	return r
+}
+
+func (r Reverb) Serial() bool {
+	return true
+}
+
+func (r Reverb) Value(at time.Duration) (float64, error) {
+	if r.wave == nil || r.state == nil {
+		return 0, ErrNoWave
+	}
+	if err := checkSampleRate(r.sampleRate); err != nil {
+		return 0, err
+	}
+	s := r.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.left.out, nil
+	case ClockReset:
+		s.reset(r)
+	}
+
+	x, err := r.wave.Value(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, err
+	}
+	return s.left.process(x, x, r.mix), nil
+}
+
+func (r Reverb) StereoValue(at time.Duration) (float64, float64, error) {
+	if r.stereoWave == nil || r.state == nil {
+		return 0, 0, ErrNoWave
+	}
+	if err := checkSampleRate(r.sampleRate); err != nil {
+		return 0, 0, err
+	}
+	s := r.state
+	switch s.clock.Advance(at) {
+	case ClockRepeat:
+		return s.left.out, s.right.out, nil
+	case ClockReset:
+		s.reset(r)
+	}
+
+	left, right, err := r.stereoWave.StereoValue(at)
+	if err != nil && !errors.Is(err, ErrEndOfWave) {
+		return 0, 0, err
+	}
+	input := (left + right) / 2
+	return s.left.process(input, left, r.mix), s.right.process(input, right, r.mix), nil
+}
+
+// WrapProcessor returns a processor that adds the reverb to the frames of the input processor.
+func (r Reverb) WrapProcessor(p Processor) Processor {
+	return &reverbProcessor{input: p, params: r}
+}
+
+type reverbProcessor struct {
+	input   Processor
+	params  Reverb
+	network *reverbNetwork
+}
+
+func (p *reverbProcessor) Process(buf []float64) error {
+	if err := checkSampleRate(p.params.sampleRate); err != nil {
+		return err
+	}
+	if p.network == nil {
+		p.network = newReverbNetwork(p.params, 0)
+	}
+	if err := p.input.Process(buf); err != nil {
+		return err
+	}
+	for i, x := range buf {
+		buf[i] = p.network.process(x, x, p.params.mix)
+	}
+	return nil
+}
+
+func (p *reverbProcessor) Reset() {
+	p.input.Reset()
+	p.network = nil
+}
+
+type reverbState struct {
+	clock       FrameClock
+	left, right *reverbNetwork
+}
+
+func (s *reverbState) reset(r Reverb) {
+	s.left, s.right = newReverbNetwork(r, 0), newReverbNetwork(r, reverbStereoSpread)
+}
+
+// reverbNetwork holds the delay lines of one channel of the reverb.
+type reverbNetwork struct {
+	preDelay  *delayLine
+	combs     []*delayLine
+	allPasses []*delayLine
+	feedback  float64
+	out       float64 // last output
+}
+
+// newReverbNetwork creates a network of a reverb with a valid sample rate,
+// the spread (in frames at 44100 Hz) is added to the length of its delay lines.
+func newReverbNetwork(r Reverb, spread int) *reverbNetwork {
+	scale := float64(r.sampleRate) / 44100
+	frames := func(tuning int) int {
+		return int(math.Round(float64(tuning+spread) * scale))
+	}
+
+	n := &reverbNetwork{feedback: 0.7 + 0.28*r.roomSize}
+	if r.preDelay > 0 {
+		n.preDelay = newRingLine(int(math.Round(r.preDelay.Seconds()*float64(r.sampleRate))), 0)
+	}
+	for _, tuning := range reverbCombTunings {
+		n.combs = append(n.combs, newRingLine(frames(tuning), 0.4*r.damping))
+	}
+	for _, tuning := range reverbAllPassTunings {
+		n.allPasses = append(n.allPasses, newRingLine(frames(tuning), 0))
+	}
+	return n
+}
+
+// process feeds the input frame to the network and returns the mix of the dry frame and of the reverb.
+func (n *reverbNetwork) process(input, dry, mix float64) float64 {
+	if n.preDelay != nil {
+		delayed := n.preDelay.read()
+		n.preDelay.write(input)
+		input = delayed
+	}
+	input *= 2 * reverbInputGain
+
+	wet := 0.0
+	for _, comb := range n.combs {
+		delayed := comb.read() // damped by the filter of the line
+		comb.write(input + n.feedback*delayed)
+		wet += delayed
+	}
+	for _, allPass := range n.allPasses {
+		delayed := allPass.read()
+		allPass.write(wet + reverbAllPassGain*delayed)
+		wet = delayed - wet
+	}
+
+	n.out = (1-mix)*dry + mix*reverbWetGain*wet
+	return n.out
+}
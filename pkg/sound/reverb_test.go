@@ -0,0 +1,88 @@
+package sound
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReverb(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := 8000
+
+	// energy returns the energy of the wave between the given frames.
+	energy := func(t *testing.T, wave Wave, from, to int) float64 {
+		sum := 0.0
+		for i := 0; i < to; i++ {
+			val, err := wave.Value(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i >= from {
+				sum += val * val
+			}
+		}
+		return sum
+	}
+
+	t.Run("Should implement the effect interfaces", func(t *testing.T) {
+		var _ Effect = Reverb{}
+		var _ StereoEffect = Reverb{}
+		var _ ProcessorEffect = Reverb{}
+	})
+
+	t.Run("Should add a decaying tail to the wave", func(t *testing.T) {
+		wave := NewReverb(sampleRate, 0.5, 0.5, 1).Wrap(impulseWave{})
+		early := energy(t, wave, 0, sampleRate/2)
+		late := energy(t, wave, sampleRate/2, sampleRate)
+		if early == 0 || late >= early {
+			t.Fatalf("want a decaying tail, got energies %f and %f", early, late)
+		}
+	})
+
+	t.Run("Should have a longer tail in bigger rooms", func(t *testing.T) {
+		small := energy(t, NewReverb(sampleRate, 0.1, 0.5, 1).Wrap(impulseWave{}), sampleRate, 2*sampleRate)
+		big := energy(t, NewReverb(sampleRate, 0.9, 0.5, 1).Wrap(impulseWave{}), sampleRate, 2*sampleRate)
+		if big <= small {
+			t.Fatalf("want more energy after a second in a big room, got %f (small) and %f (big)", small, big)
+		}
+	})
+
+	t.Run("Should start after the pre-delay", func(t *testing.T) {
+		wave := NewReverb(sampleRate, 0.5, 0.5, 1).WithPreDelay(200 * time.Millisecond).Wrap(impulseWave{})
+		// the shortest path through the network is the shortest comb
+		silence := sampleRate/5 + int(float64(reverbCombTunings[0])*float64(sampleRate)/44100)
+		if got := energy(t, wave, 0, silence); got != 0 {
+			t.Fatalf("want silence during the pre-delay but got an energy of %f", got)
+		}
+		if got := energy(t, wave, silence, sampleRate); got == 0 {
+			t.Fatal("want the reverb after the pre-delay")
+		}
+	})
+
+	t.Run("Should let the wave through when dry", func(t *testing.T) {
+		wave := NewReverb(sampleRate, 0.5, 0.5, 0).Wrap(NewSynthWave(Sine{}, 440))
+		for i := 0; i < 100; i++ {
+			want, _ := NewSynthWave(Sine{}, 440).Value(FrameTime(0, i, sampleRate))
+			if got, _ := wave.Value(FrameTime(0, i, sampleRate)); got != want {
+				t.Fatalf("unexpected frame value at index %d, want %f but got %f", i, want, got)
+			}
+		}
+	})
+
+	t.Run("Should produce different reflections in each channel", func(t *testing.T) {
+		wave := NewReverb(sampleRate, 0.5, 0.5, 1).WrapStereo(UpMix(impulseWave{}))
+		diff := 0.0
+		for i := 0; i < sampleRate; i++ {
+			left, right, err := wave.StereoValue(FrameTime(0, i, sampleRate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff = math.Max(diff, math.Abs(left-right))
+		}
+		if diff == 0 {
+			t.Fatal("want the left and right channels to be different")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// StereoWave is like Wave, but it produces a value for both the left and right channels.
+type StereoWave interface {
+	StereoValue(elapsed time.Duration) (left, right float64, err error)
+}
+
+// StereoEffect is implemented by effects that produce a stereo output (for ex: a ping-pong delay).
+type StereoEffect interface {
+	WrapStereo(StereoWave) StereoWave
+}
+
+// PannedWave places a mono wave in the stereo field.
+type PannedWave struct {
+	wave Wave
+	pan  float64
+}
+
+// NewPannedWave creates a stereo wave from a mono wave.
+// The pan goes from -1 (left only) to 1 (right only), 0 plays the wave at full volume in both channels.
+func NewPannedWave(wave Wave, pan float64) PannedWave {
+	return PannedWave{wave: wave, pan: math.Max(-1, math.Min(1, pan))}
+}
+
+// UpMix converts a mono wave into a stereo wave that plays the same signal in both channels.
+func UpMix(wave Wave) PannedWave {
+	return NewPannedWave(wave, 0)
+}
+
+func (w PannedWave) Serial() bool {
+	return IsSerial(w.wave)
+}
+
+func (w PannedWave) StereoValue(at time.Duration) (float64, float64, error) {
+	val, err := w.wave.Value(at)
+	if err != nil {
+		return 0, 0, err
+	}
+	return val * math.Min(1, 1-w.pan), val * math.Min(1, 1+w.pan), nil
+}
+
+// DualWave is a stereo wave made of two independant mono waves.
+type DualWave struct {
+	left  Wave
+	right Wave
+}
+
+// NewDualWave creates a stereo wave that plays one wave in each channel.
+func NewDualWave(left, right Wave) DualWave {
+	return DualWave{left: left, right: right}
+}
+
+func (w DualWave) Serial() bool {
+	return IsSerial(w.left) || IsSerial(w.right)
+}
+
+func (w DualWave) StereoValue(at time.Duration) (float64, float64, error) {
+	left, err := w.left.Value(at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to get value from left wave: %w", err)
+	}
+	right, err := w.right.Value(at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to get value from right wave: %w", err)
+	}
+	return left, right, nil
+}
+
+// DownMix converts a stereo wave into a mono wave by averaging both channels.
+type DownMix struct {
+	wave StereoWave
+}
+
+// NewDownMix creates a mono wave from a stereo wave.
+func NewDownMix(wave StereoWave) DownMix {
+	return DownMix{wave: wave}
+}
+
+func (w DownMix) Serial() bool {
+	return IsSerial(w.wave)
+}
+
+func (w DownMix) Value(at time.Duration) (float64, error) {
+	left, right, err := w.wave.StereoValue(at)
+	if err != nil {
+		return 0, err
+	}
+	return (left + right) / 2, nil
+}
+
+// MergedStereoWaves combines several stereo waves into one, like MergedWaves does for mono waves.
+type MergedStereoWaves struct {
+	waves []StereoWave
+}
+
+func NewMergedStereoWaves(waves ...StereoWave) MergedStereoWaves {
+	return MergedStereoWaves{waves: waves}
+}
+
+func (w MergedStereoWaves) Serial() bool {
+	for _, wave := range w.waves {
+		if IsSerial(wave) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w MergedStereoWaves) StereoValue(x time.Duration) (float64, float64, error) {
+	outLeft, outRight := 0.0, 0.0
+	for _, wave := range w.waves {
+		left, right, err := wave.StereoValue(x)
+		if err != nil {
+			if errors.Is(err, ErrEndOfWave) {
+				continue
+			}
+			return 0, 0, err
+		}
+		outLeft += left
+		outRight += right
+	}
+	return outLeft / float64(len(w.waves)), outRight / float64(len(w.waves)), nil
+}
@@ -0,0 +1,101 @@
+package sound
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPannedWave(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should implement StereoWave", func(t *testing.T) {
+		var _ StereoWave = PannedWave{}
+	})
+
+	t.Run("Should place the wave in the stereo field", func(t *testing.T) {
+		tests := []struct {
+			pan       float64
+			wantLeft  float64
+			wantRight float64
+		}{
+			{pan: 0, wantLeft: 1, wantRight: 1},
+			{pan: -1, wantLeft: 1, wantRight: 0},
+			{pan: 1, wantLeft: 0, wantRight: 1},
+			{pan: 0.5, wantLeft: 0.5, wantRight: 1},
+			{pan: -3, wantLeft: 1, wantRight: 0}, // out of range pan is clamped
+		}
+
+		for _, test := range tests {
+			left, right, err := NewPannedWave(MockWave{}, test.pan).StereoValue(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(left-test.wantLeft) > 0.0001 || math.Abs(right-test.wantRight) > 0.0001 {
+				t.Fatalf("pan %f: want (%f, %f) but got (%f, %f)", test.pan, test.wantLeft, test.wantRight, left, right)
+			}
+		}
+	})
+
+	t.Run("Should up-mix mono waves to both channels", func(t *testing.T) {
+		wave := NewSynthWave(Sine{}, 3)
+		for _, at := range []time.Duration{0, 10 * time.Millisecond, 70 * time.Millisecond} {
+			want, _ := wave.Value(at)
+			left, right, _ := UpMix(wave).StereoValue(at)
+			if left != want || right != want {
+				t.Fatalf("want %f in both channels but got (%f, %f)", want, left, right)
+			}
+		}
+	})
+}
+
+func TestDualWave(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should play one wave in each channel", func(t *testing.T) {
+		left, right, err := NewDualWave(MockWave{}, SilentWave{}).StereoValue(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left != 1 || right != 0 {
+			t.Fatalf("want (1, 0) but got (%f, %f)", left, right)
+		}
+	})
+
+	t.Run("Should be mixed down to mono", func(t *testing.T) {
+		got, err := NewDownMix(NewDualWave(MockWave{}, SilentWave{})).Value(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 0.5 {
+			t.Fatalf("want 0.5 but got %f", got)
+		}
+	})
+}
+
+func TestMergedStereoWaves(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should average channels and ignore ended waves", func(t *testing.T) {
+		ended := NewPannedWave(NewPattern([]PatternSegment{}), 0)
+		merged := NewMergedStereoWaves(
+			NewPannedWave(MockWave{}, -1),
+			NewPannedWave(MockWave{}, 0),
+			ended,
+		)
+
+		_, _, err := ended.StereoValue(0)
+		if !errors.Is(err, ErrEndOfWave) {
+			t.Fatalf("want %s but got %v", ErrEndOfWave, err)
+		}
+
+		left, right, err := merged.StereoValue(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(left-2.0/3) > 0.0001 || math.Abs(right-1.0/3) > 0.0001 {
+			t.Fatalf("want (%f, %f) but got (%f, %f)", 2.0/3, 1.0/3, left, right)
+		}
+	})
+}
@@ -23,6 +23,10 @@ func NewSynthWave(synth Synthesizer, frequency float64) SynthWave {
 	return SynthWave{synth: synth, freq: frequency}
 }
 
+func (w SynthWave) Serial() bool {
+	return IsSerial(w.synth)
+}
+
 func (w SynthWave) Value(at time.Duration) (float64, error) {
 	return w.synth.Synthesize(w.freq, at)
 }
@@ -61,6 +65,11 @@ func NewRandomWideBandNoiseSynthesizer(seed int64) RandomWideBandNoiseSynthesize
 	return RandomWideBandNoiseSynthesizer{rand: rand.New(rand.NewSource(seed))}
 }
 
+// Serial always returns true as each call produces the next random value.
+func (p RandomWideBandNoiseSynthesizer) Serial() bool {
+	return true
+}
+
 func (p RandomWideBandNoiseSynthesizer) Synthesize(freq float64, x time.Duration) (float64, error) {
 	return (p.rand.Float64() * 2) - 1.0, nil
 }
@@ -18,6 +18,21 @@ type Wave interface {
 // ErrEndOfWave means that the wave has ended.
 var ErrEndOfWave = errors.New("end of wave")
 
+// SerialWave is implemented by waves and synthesizers that must be rendered serially,
+// one frame after the other and in order, because their output depends on previous calls (for ex: random noise).
+// Waves that don't implement it are expected to be pure functions of time and safe for concurrent use.
+//
+// Waves that wrap other waves should implement it to report whether one of their wrapped waves is serial.
+type SerialWave interface {
+	Serial() bool
+}
+
+// IsSerial reports whether the provided wave or synthesizer must be rendered serially.
+func IsSerial(v interface{}) bool {
+	serial, ok := v.(SerialWave)
+	return ok && serial.Serial()
+}
+
 // MockWave is a wave that always produces the value of one.
 // Useful for testing and debugging.
 type MockWave struct{}
@@ -43,6 +58,15 @@ func NewMergedWaves(waves ...Wave) MergedWaves {
 	return MergedWaves{waves: waves}
 }
 
+func (w MergedWaves) Serial() bool {
+	for _, wave := range w.waves {
+		if IsSerial(wave) {
+			return true
+		}
+	}
+	return false
+}
+
 func (w MergedWaves) Value(x time.Duration) (float64, error) {
 	out := 0.0
 	for _, wave := range w.waves {
@@ -58,3 +82,26 @@ func (w MergedWaves) Value(x time.Duration) (float64, error) {
 	}
 	return out / float64(len(w.waves)), nil
 }
+
+// AmplifiedWave multiplies the values of a wave by a constant gain.
+type AmplifiedWave struct {
+	wave Wave
+	gain float64
+}
+
+// NewAmplifiedWave creates a wave that plays the input wave with the given gain (for ex: 0.5 to halve its amplitude).
+func NewAmplifiedWave(wave Wave, gain float64) AmplifiedWave {
+	return AmplifiedWave{wave: wave, gain: gain}
+}
+
+func (w AmplifiedWave) Serial() bool {
+	return IsSerial(w.wave)
+}
+
+func (w AmplifiedWave) Value(x time.Duration) (float64, error) {
+	val, err := w.wave.Value(x)
+	if err != nil {
+		return 0, err
+	}
+	return val * w.gain, nil
+}
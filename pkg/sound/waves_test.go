@@ -1,6 +1,7 @@
 package sound
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -30,3 +31,49 @@ func TestWithAmplitude(t *testing.T) {
 		}
 	})
 }
+
+func TestIsSerial(t *testing.T) {
+	t.Parallel()
+
+	noise := NewSynthWave(NewRandomWideBandNoiseSynthesizer(0), 0)
+	sine := NewSynthWave(Sine{}, 440)
+
+	tests := []struct {
+		wave Wave
+		want bool
+	}{
+		{wave: sine, want: false},
+		{wave: MockWave{}, want: false},
+		{wave: noise, want: true},
+		{wave: NewMergedWaves(sine, noise), want: true},
+		{wave: NewMergedWaves(sine, sine), want: false},
+		{wave: NewPattern([]PatternSegment{{Duration: time.Second, Wave: noise}}), want: true},
+		{wave: NewPattern([]PatternSegment{{Duration: time.Second}}), want: false},
+		{wave: WithAmplitude(nil, 1).Wrap(noise), want: true},
+		{wave: NewDownMix(NewDualWave(sine, noise)), want: true},
+		{wave: NewAmplifiedWave(noise, 0.5), want: true},
+	}
+
+	for i, test := range tests {
+		if got := IsSerial(test.wave); got != test.want {
+			t.Fatalf("test %d: want %v but got %v", i, test.want, got)
+		}
+	}
+}
+
+func TestAmplifiedWave(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewAmplifiedWave(MockWave{}, 0.5).Value(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0.5 {
+		t.Fatalf("want 0.5 but got %f", got)
+	}
+
+	_, err = NewAmplifiedWave(NewKick(), 0.5).Value(time.Minute)
+	if !errors.Is(err, ErrEndOfWave) {
+		t.Fatalf("want error %s but got %v", ErrEndOfWave, err)
+	}
+}